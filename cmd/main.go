@@ -1,10 +1,20 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"tetris/internal/engine"
+	"tetris/internal/figure"
 	"tetris/internal/game"
+	"tetris/internal/highscore"
+	"tetris/internal/input"
+	"tetris/internal/replay"
+	"tetris/internal/savegame"
+	"tetris/internal/scene"
+	"tetris/internal/settings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -15,16 +25,148 @@ func main() {
 	// Установка префикса для логов
 	log.SetPrefix("main: ")
 
-	log.Println("Запуск игры Tetris") // Логируем запуск игры
+	// Сохраненные настройки служат значениями флагов по умолчанию, флаги меняют их только на этот запуск
+	settingsPath, err := settings.DefaultPath()
+	if err != nil {
+		log.Printf("настройки не будут сохраняться: %v", err)
+	}
+	st := settings.Default()
+	if settingsPath != "" {
+		if st, err = settings.Load(settingsPath); err != nil {
+			log.Printf("используются настройки по умолчанию: %v", err)
+		}
+	}
+	randomizer := flag.String("randomizer", string(st.Randomizer), "генератор фигур: bag7, bag14, random, history, sequence")
+	seed := flag.Uint64("seed", 0, "зерно генератора фигур (0 - случайное)")
+	sequence := flag.String("sequence", "", "последовательность фигур для генератора sequence, например IOTSZJL")
+	width := flag.Int("width", st.Width, "ширина поля в клетках (4-16)")
+	height := flag.Int("height", st.Height, "высота видимой части поля в клетках (4-40)")
+	nextCount := flag.Int("next", st.NextCount, "длина очереди следующих фигур (1-6)")
+	startLevel := flag.Int("level", st.StartLevel, "начальный уровень (1-20)")
+	lockMode := flag.String("lock", string(st.LockMode), "режим задержки фиксации: extended, infinity, classic")
+	lockDelay := flag.Duration("lock-delay", st.LockDelay, "задержка фиксации фигуры на опоре")
+	lineClearDelay := flag.Duration("line-clear-delay", st.LineClearDelay, "сколько исчезают заполненные ряды (0-1s)")
+	entryDelay := flag.Duration("are", st.EntryDelay, "задержка появления следующей фигуры (ARE, 0-1s)")
+	defaultBindings, err := input.DefaultBindingsPath()
+	if err != nil {
+		log.Printf("раскладка клавиш не будет сохраняться: %v", err)
+	}
+	bindingsPath := flag.String("bindings", defaultBindings, "файл с раскладкой клавиш (JSON)")
+	defaultGamepads, err := input.DefaultGamepadProfilesPath()
+	if err != nil {
+		log.Printf("раскладки геймпадов будут по умолчанию: %v", err)
+	}
+	gamepadsPath := flag.String("gamepads", defaultGamepads, "файл с раскладками геймпадов по SDL ID (JSON)")
+	players := flag.Int("players", st.Players, "количество локальных игроков (1-4)")
+	recordPath := flag.String("record", "", "записывать повтор последней игры в файл")
+	replayPath := flag.String("replay", "", "воспроизвести повтор из файла")
+	defaultSaves, err := savegame.DefaultDir()
+	if err != nil {
+		log.Printf("сохранения будут недоступны: %v", err)
+	}
+	saveDir := flag.String("saves", defaultSaves, "каталог сохранений игры (пусто - без сохранений)")
+	autosave := flag.Bool("autosave", false, "сохранить игру при выходе и продолжить её при следующем запуске")
+	defaultScores, err := highscore.DefaultPath()
+	if err != nil {
+		log.Printf("рекорды не будут сохраняться: %v", err)
+	}
+	scoresPath := flag.String("scores", defaultScores, "файл таблицы рекордов (пусто - без рекордов)")
+	// Флаги управления относятся к первому игроку, управление остальных задается на экране настроек
+	das := flag.Duration("das", st.Handling[0].DAS, "задержка перед автоповтором сдвига (DAS) первого игрока")
+	arr := flag.Duration("arr", st.Handling[0].ARR, "интервал автоповтора сдвига (ARR) первого игрока, 0 - сразу до упора")
+	dasCut := flag.Duration("das-cut", st.Handling[0].DASCut, "пауза автоповтора после появления новой фигуры у первого игрока")
+	sdf := flag.Int("sdf", st.Handling[0].SDF, "во сколько раз ускоряется падение при нажатой клавише вниз (SDF) у первого игрока, 0 - сразу до опоры")
+	flag.Parse()
+
+	// В файл настроек попадают только изменения, сделанные в меню, а не значения флагов
+	saved := st
+	st.Players = *players
+	st.Randomizer = figure.RandomizerKind(*randomizer)
+	st.NextCount = *nextCount
+	st.Width, st.Height = *width, *height
+	st.StartLevel = *startLevel
+	st.LockMode = engine.LockMode(*lockMode)
+	st.LockDelay = *lockDelay
+	st.LineClearDelay, st.EntryDelay = *lineClearDelay, *entryDelay
+	st.Handling[0] = engine.Handling{DAS: *das, ARR: *arr, DASCut: *dasCut, SDF: *sdf}
+	cfg := engine.DefaultConfig()
+	st.Apply(&cfg)
+	cfg.Seed = *seed
+	if cfg.Seed == 0 {
+		cfg.Seed = uint64(time.Now().UnixNano())
+	}
+	if *sequence != "" {
+		shapes, err := figure.ParseShapes(*sequence)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Неверная последовательность фигур: %v\n", err)
+			os.Exit(2)
+		}
+		cfg.Sequence = shapes
+	}
+	// Проверяем параметры сразу, чтобы ошибка во флагах не обнаружилась только при старте игры
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Неверные параметры игры: %v\n", err)
+		os.Exit(2)
+	}
+	if st.Players < 1 || st.Players > settings.MaxPlayers {
+		fmt.Fprintf(os.Stderr, "Количество игроков %d вне диапазона 1-%d\n", st.Players, settings.MaxPlayers)
+		os.Exit(2)
+	}
+
+	log.Printf("Запуск игры Tetris (генератор %s, зерно %d)", cfg.Randomizer, cfg.Seed) // Логируем запуск игры
 
-	gameInstance := game.NewGame()
+	bindings := input.DefaultBindings()
+	if *bindingsPath != "" {
+		if bindings, err = input.LoadBindings(*bindingsPath); err != nil {
+			log.Printf("используется раскладка по умолчанию: %v", err)
+		}
+	}
+	profiles := input.GamepadProfiles{}
+	if *gamepadsPath != "" {
+		if profiles, err = input.LoadGamepadProfiles(*gamepadsPath); err != nil {
+			log.Printf("используются раскладки геймпадов по умолчанию: %v", err)
+		}
+	}
+	opts := scene.Options{
+		Config:          cfg,
+		Settings:        st,
+		SavedSettings:   saved,
+		SettingsPath:    settingsPath,
+		Bindings:        bindings,
+		BindingsPath:    *bindingsPath,
+		GamepadProfiles: profiles,
+		RecordPath:      *recordPath,
+		SaveDir:         *saveDir,
+		Autosave:        *autosave,
+		ScoresPath:      *scoresPath,
+	}
+	if *replayPath != "" {
+		rep, err := replay.Load(*replayPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка при загрузке повтора: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Replay = rep
+		log.Printf("Просмотр повтора %s", *replayPath)
+	}
+
+	app, err := scene.NewManager(opts)
+	if err != nil {
+		log.Printf("Ошибка при создании игры: %v", err)
+		fmt.Fprintf(os.Stderr, "Ошибка при создании игры: %v\n", err)
+		os.Exit(1)
+	}
+	ebiten.SetWindowSize(game.ScreenSize(st.Players))
+	ebiten.SetWindowTitle("Tetris")
 	// Обработка ошибки, которую может вернуть ebiten.RunGame
-	if err := ebiten.RunGame(gameInstance); err != nil {
+	if err := ebiten.RunGame(app); err != nil {
 		// Логируем ошибку
 		log.Printf("Ошибка при запуске игры: %v", err)
 		// Выводим ошибку в stderr с помощью fmt.Fprintf
 		fmt.Fprintf(os.Stderr, "Ошибка при запуске игры: %v\n", err)
 		os.Exit(1) // Завершаем программу с ненулевым кодом возврата
 	}
+	// Незаконченная игра сохраняется, а её повтор записывается
+	app.Close()
 	log.Println("Игра Tetris завершена")
 }
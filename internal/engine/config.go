@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"fmt"
+	"tetris/internal/field"
+	"tetris/internal/figure"
+	"tetris/internal/models"
+	"time"
+)
+
+const (
+	MinNextCount     = 1 // MinNextCount - Минимальная длина очереди следующих фигур
+	MaxNextCount     = 6 // MaxNextCount - Максимальная длина очереди следующих фигур
+	defaultNextCount = 5
+	defaultLockDelay = time.Millisecond * 500
+)
+
+// Config задает параметры новой игры
+type Config struct {
+	Mode       Mode                  // Режим игры
+	Width      int                   // Ширина поля в клетках
+	Height     int                   // Высота видимой части поля в клетках
+	Randomizer figure.RandomizerKind // Алгоритм генератора фигур
+	Seed       uint64                // Зерно генератора фигур
+	Sequence   []models.Shape        // Последовательность для генератора RandomizerSequence
+	NextCount  int                   // Сколько следующих фигур видно в очереди (1-6)
+	LockDelay  time.Duration         // Сколько фигура может лежать на опоре до фиксации
+	LockMode   LockMode              // Правило сброса задержки фиксации
+	//Задержки между фигурами
+	LineClearDelay time.Duration // Сколько исчезают заполненные ряды перед сдвигом поля
+	EntryDelay     time.Duration // Задержка появления следующей фигуры после фиксации (ARE)
+	StartLevel     int           // Начальный уровень (1-20)
+	Handling       Handling      // Настройки управления игрока (DAS/ARR/SDF)
+	LineGoal       int           // Сколько линий нужно очистить в режиме Sprint
+	TimeLimit      time.Duration // Длительность игры в режиме Ultra
+	//Cheese
+	GarbageRows     int           // Сколько мусорных рядов на поле в начале
+	Messiness       int           // Вероятность, что дырка следующего ряда окажется в другой колонке, %
+	GarbageInterval time.Duration // Как часто снизу поднимается новый ряд (0 - не поднимается)
+}
+
+// DefaultConfig возвращает настройки по умолчанию
+func DefaultConfig() Config {
+	return Config{
+		Mode:            ModeMarathon,
+		Width:           field.DefaultWidth,
+		Height:          field.DefaultHeight,
+		Randomizer:      figure.RandomizerBag7,
+		NextCount:       defaultNextCount,
+		LockDelay:       defaultLockDelay,
+		LockMode:        LockExtended,
+		LineClearDelay:  defaultLineClearDelay,
+		EntryDelay:      defaultEntryDelay,
+		StartLevel:      MinStartLevel,
+		Handling:        DefaultHandling(),
+		LineGoal:        DefaultSprintGoal,
+		TimeLimit:       DefaultUltraTime,
+		GarbageRows:     DefaultGarbageRows,
+		Messiness:       DefaultMessiness,
+		GarbageInterval: DefaultGarbageInterval,
+	}
+}
+
+// Validate проверяет, что настройки допустимы
+func (c Config) Validate() error {
+	if err := validateMode(c.Mode); err != nil {
+		return err
+	}
+	if c.Width < field.MinWidth || c.Width > field.MaxWidth {
+		return fmt.Errorf("ширина поля %d вне диапазона %d-%d", c.Width, field.MinWidth, field.MaxWidth)
+	}
+	if c.Height < field.MinHeight || c.Height > field.MaxHeight {
+		return fmt.Errorf("высота поля %d вне диапазона %d-%d", c.Height, field.MinHeight, field.MaxHeight)
+	}
+	if c.Mode == ModeSprint && c.LineGoal <= 0 {
+		return fmt.Errorf("цель режима Sprint должна быть положительной: %d линий", c.LineGoal)
+	}
+	if c.Mode == ModeUltra && c.TimeLimit <= 0 {
+		return fmt.Errorf("время режима Ultra должно быть положительным: %s", c.TimeLimit)
+	}
+	if c.Mode == ModeCheese {
+		if err := c.validateGarbage(); err != nil {
+			return err
+		}
+	}
+	if c.NextCount < MinNextCount || c.NextCount > MaxNextCount {
+		return fmt.Errorf("длина очереди фигур %d вне диапазона %d-%d", c.NextCount, MinNextCount, MaxNextCount)
+	}
+	if c.LockDelay <= 0 {
+		return fmt.Errorf("задержка фиксации должна быть положительной: %s", c.LockDelay)
+	}
+	if c.LineClearDelay < 0 || c.LineClearDelay > MaxLineClearDelay {
+		return fmt.Errorf("задержка очистки линий %s вне диапазона 0-%s", c.LineClearDelay, MaxLineClearDelay)
+	}
+	if c.EntryDelay < 0 || c.EntryDelay > MaxEntryDelay {
+		return fmt.Errorf("задержка появления фигуры %s вне диапазона 0-%s", c.EntryDelay, MaxEntryDelay)
+	}
+	if c.StartLevel < MinStartLevel || c.StartLevel > MaxStartLevel {
+		return fmt.Errorf("начальный уровень %d вне диапазона %d-%d", c.StartLevel, MinStartLevel, MaxStartLevel)
+	}
+	switch c.LockMode {
+	case LockExtended, LockInfinity, LockClassic:
+	default:
+		return fmt.Errorf("неизвестный режим фиксации: %q", c.LockMode)
+	}
+	return c.Handling.Validate()
+}
+
+// validateGarbage проверяет настройки мусора режима Cheese
+func (c Config) validateGarbage() error {
+	if maxRows := MaxGarbageRows(c.Height); c.GarbageRows < 1 || c.GarbageRows > maxRows {
+		return fmt.Errorf("количество мусорных рядов %d вне диапазона 1-%d", c.GarbageRows, maxRows)
+	}
+	if c.Messiness < 0 || c.Messiness > 100 {
+		return fmt.Errorf("беспорядок мусора %d%% вне диапазона 0-100%%", c.Messiness)
+	}
+	if c.GarbageInterval < 0 {
+		return fmt.Errorf("интервал подъема мусора не может быть отрицательным: %s", c.GarbageInterval)
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+	"tetris/internal/field"
+	"tetris/internal/figure"
+	"tetris/internal/models"
+	"time"
+)
+
+// Version - версия правил движка. Повторы совместимы только с той же версией:
+// её нужно увеличивать при любом изменении, влияющем на результат Step.
+const Version = 10
+
+// Engine хранит состояние игры и применяет правила без привязки к окну, клавиатуре и часам
+type Engine struct {
+	Config     Config // Настройки, с которыми создана игра
+	Field      *field.Field
+	Figure     *models.Figure
+	Randomizer figure.Randomizer // Генератор фигур
+	Next       []models.Shape    // Очередь следующих фигур, первая появится следующей
+	//Отложенная фигура
+	Hold      models.Shape // Фигура в слоте удержания
+	HasHold   bool         // Есть ли фигура в слоте удержания
+	HoldUsed  bool         // Использован ли обмен для текущей фигуры (сбрасывается при фиксации)
+	GameOver  bool
+	Completed bool          // Игра закончена достижением цели режима, а не проигрышем
+	Reason    Reason        // Причина окончания игры
+	Ready     time.Duration // Сколько осталось до начала игры после отсчета "Ready" (0 - игра идет)
+	//Этап
+	Phase        Phase         // Текущий этап: падение фигуры, очистка линий или задержка появления
+	PhaseTimer   time.Duration // Сколько осталось до конца этапа очистки линий или задержки появления
+	ClearingRows []int         // Заполненные ряды, которые исчезают на этапе очистки линий
+	//Счет
+	Score      int  // Текущий счет
+	Level      int  // Текущий уровень
+	Lines      int  // Всего очищено линий
+	Combo      int  // Номер очистки в текущей серии подряд (-1 - серии нет)
+	BackToBack bool // Была ли последняя очистка сложной (Tetris или T-Spin)
+	//Статистика
+	Time          time.Duration   // Время игры без учета пауз
+	Pieces        int             // Сколько фигур зафиксировано
+	Keys          int             // Сколько раз нажаты игровые клавиши
+	FinesseFaults int             // Сколько лишних нажатий сделано по сравнению с оптимальной техникой
+	Splits        []time.Duration // Время, к которому очищен каждый очередной десяток линий
+	Clears        ClearStats      // Сколько было очисток каждого вида
+	//Мусор
+	GarbageLeft   int      // Сколько мусорных рядов осталось раскопать
+	garbage       *garbage // Генератор мусорных рядов (только в режиме Cheese)
+	pieceInputs   int      // Нажатия сдвига и поворота для текущей фигуры
+	pieceSoftDrop bool     // Опускалась ли текущая фигура ускоренным падением
+	//Последняя очистка
+	LastClear      Clear         // Результат последней фиксации, очистившей линии или давшей T-Spin
+	SinceLastClear time.Duration // Сколько прошло с последней такой фиксации
+	//Пауза
+	Paused bool //На паузе ли игра?
+	//Таймеры, накапливаемые из dt
+	gravity float64    // Накопленная дробная часть падения в строках
+	shift   shiftState // Автоповтор горизонтального сдвига (DAS/ARR)
+	//Задержка фиксации
+	LockResets int           // Сколько раз задержка фиксации уже сброшена движением
+	lockTimer  time.Duration // Сколько фигура пролежала на опоре
+	lowestY    int           // Самая нижняя строка, которой достигла фигура
+	//Данные для определения T-Spin
+	lastMoveRotation bool                     // Было ли последнее успешное движение фигуры поворотом
+	lastKick         int                      // Номер теста смещения SRS при последнем повороте
+	lastDirection    figure.RotationDirection // Направление последнего поворота
+	prevInput        Input                    // Состояние клавиш в предыдущем кадре, чтобы отличать нажатие от удержания
+}
+
+// NewEngine создает новое состояние игры с заданными настройками
+func NewEngine(cfg Config) (*Engine, error) {
+	if cfg.Mode == "" {
+		// Повторы, записанные до появления режимов, - это марафон
+		cfg.Mode = ModeMarathon
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	rnd, err := figure.NewRandomizer(cfg.Randomizer, cfg.Seed, cfg.Sequence)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать генератор фигур: %w", err)
+	}
+	e := &Engine{
+		Config:     cfg,
+		Field:      field.NewField(cfg.Width, cfg.Height, field.BufferRows),
+		Randomizer: rnd,
+		Level:      cfg.Mode.startLevel(cfg.StartLevel),
+		Combo:      -1,
+		Ready:      cfg.Mode.ReadyTime(),
+		GameOver:   false,
+		Score:      0, // Изначальный счет - 0
+		Paused:     false,
+	}
+	for range cfg.NextCount {
+		e.Next = append(e.Next, e.Randomizer.Next())
+	}
+	if cfg.Mode == ModeCheese {
+		e.garbage = newGarbage(cfg.Seed, cfg.Width)
+		e.fillGarbage()
+	}
+	e.spawnFigure(e.takeNext())
+	return e, nil
+}
+
+// Step продвигает игру на один кадр длительностью dt с заданным состоянием клавиш
+func (e *Engine) Step(in Input, dt time.Duration) {
+	e.SinceLastClear += dt
+	prev := e.prevInput
+	e.prevInput = in
+
+	// Пауза, перезапуск, удержание и повороты срабатывают только в момент нажатия
+	if in.Pause && !prev.Pause {
+		e.Paused = !e.Paused
+	}
+
+	if e.GameOver && in.Restart && !prev.Restart {
+		e.RestartGame()
+		return
+	}
+	if e.GameOver || e.Paused {
+		return
+	}
+	// Во время отсчета перед стартом фигура стоит, а время игры не идет
+	if e.Ready > 0 {
+		e.Ready = max(e.Ready-dt, 0)
+		return
+	}
+	e.Time += dt
+	if e.timeUp() {
+		e.Time = e.Config.TimeLimit
+		e.endGame(ReasonTimeUp)
+		return
+	}
+	e.countInputs(in, prev)
+
+	// На этапах очистки линий и задержки появления фигуры нет, мусор тоже не поднимается
+	if e.Phase != PhaseFalling {
+		e.chargeShift(in, prev, dt)
+		e.updatePhase(dt)
+		return
+	}
+
+	// Подъем мусора снизу
+	e.updateGarbage(dt)
+	if e.GameOver {
+		return
+	}
+
+	// Обработка горизонтальных перемещений
+	e.updateShift(in, prev, dt)
+
+	// Удержание фигуры
+	if in.Hold && !prev.Hold && !e.HoldUsed {
+		e.holdFigure()
+		if e.GameOver {
+			return
+		}
+	}
+
+	// Поворот
+	if dir, ok := rotationDirection(in, prev); ok {
+		e.rotate(dir)
+	}
+
+	// Мгновенный сброс срабатывает только в момент нажатия
+	if in.HardDrop && !prev.HardDrop {
+		e.hardDrop()
+		return
+	}
+
+	// Автоматическое падение фигуры со скоростью текущего уровня, ускоренное при нажатой клавише вниз
+	e.applyGravity(dt, in.Down)
+
+	// Фигура на дне или на другой фигуре фиксируется после задержки
+	e.updateLock(dt)
+}
+
+// hardDrop мгновенно опускает фигуру до упора и фиксирует её
+func (e *Engine) hardDrop() {
+	dist := figure.DropDistance(e.Figure, e.Field)
+	e.Figure.Y += dist
+	e.Score += dist * hardDropScore
+	if dist > 0 {
+		e.lastMoveRotation = false
+	}
+	log.Printf("фигура %s сброшена на %d строк", e.Figure.Shape, dist)
+	e.lockFigure()
+}
+
+// lockFigure фиксирует фигуру и начинает очистку заполненных рядов. Все заполненные ряды
+// находятся сразу и засчитываются в момент фиксации, а исчезают вместе после задержки очистки линий.
+// Цель режима тоже проверяется при фиксации, чтобы задержка не попадала во время игры.
+func (e *Engine) lockFigure() {
+	tSpin := e.detectTSpin()
+	e.checkFinesse()
+	e.FixFigure()
+	e.Pieces++
+	// Фигура, не опустившаяся в видимую часть поля, заканчивает игру еще до очистки линий
+	if e.lockedOut() {
+		e.endGame(ReasonLockOut)
+		return
+	}
+	e.ClearingRows = e.Field.FullRows()
+	e.scoreClear(Clear{Lines: len(e.ClearingRows), TSpin: tSpin, PerfectClear: e.perfectClear()})
+	e.GarbageLeft -= e.garbageRowsIn(e.ClearingRows)
+	if e.goalReached() {
+		// Игра закончена: ряды убираются сразу, а следующая фигура не появляется и не берется из очереди
+		e.Field.ClearRows(e.ClearingRows)
+		e.ClearingRows = nil
+		e.endGame(ReasonGoal)
+		return
+	}
+	if len(e.ClearingRows) > 0 && e.Config.LineClearDelay > 0 {
+		e.startPhase(PhaseLineClear, e.Config.LineClearDelay)
+		return
+	}
+	e.collapseRows()
+}
+
+// spawnFigure создает фигуру в точке появления над видимой частью поля и сразу опускает
+// её на строку, если есть место. Если места нет в самой точке появления, игра заканчивается.
+func (e *Engine) spawnFigure(shape models.Shape) {
+	e.Figure = figure.NewFigure(e.Field, shape)
+	e.gravity = 0
+	e.shift.cut = e.Config.Handling.DASCut
+	e.pieceInputs, e.pieceSoftDrop = 0, false
+	e.startPhase(PhaseFalling, 0)
+
+	if e.IsFigureColliding() {
+		e.endGame(ReasonBlockOut)
+	} else {
+		figure.MoveDown(e.Figure, e.Field)
+	}
+	e.resetLockState()
+}
+
+// holdFigure меняет текущую фигуру на отложенную (или на следующую, если слот пуст)
+func (e *Engine) holdFigure() {
+	current := e.Figure.Shape
+	next := e.Hold
+	if !e.HasHold {
+		next = e.takeNext()
+	}
+	e.Hold, e.HasHold, e.HoldUsed = current, true, true
+	log.Printf("фигура %s отложена", current)
+	e.spawnFigure(next)
+}
+
+// takeNext забирает первую фигуру из очереди и пополняет очередь из генератора
+func (e *Engine) takeNext() models.Shape {
+	shape := e.Next[0]
+	e.Next = append(e.Next[1:], e.Randomizer.Next())
+	return shape
+}
+
+// rotationDirection возвращает направление поворота для только что нажатых клавиш
+func rotationDirection(in, prev Input) (figure.RotationDirection, bool) {
+	switch {
+	case in.Rotate && !prev.Rotate:
+		return figure.RotateCW, true
+	case in.RotateCCW && !prev.RotateCCW:
+		return figure.RotateCCW, true
+	case in.Rotate180 && !prev.Rotate180:
+		return figure.Rotate180, true
+	}
+	return 0, false
+}
+
+// FixFigure фиксирует фигуру в поле. Клетки фигуры запоминают её тип.
+func (e *Engine) FixFigure() {
+	e.Field.Place(figure.Mask(e.Figure), e.Figure.X, e.Figure.Y, field.CellOf(e.Figure.Shape))
+}
+
+// IsFigureColliding проверяет, сталкивается ли фигура
+func (e *Engine) IsFigureColliding() bool {
+	return figure.IsFigureCollidingAfterMove(e.Figure, e.Field, 0, 0)
+}
+
+// IsFigureCollidingAfterMove проверяет, будет ли столкновение после сдвига фигуры на клетку вниз
+func (e *Engine) IsFigureCollidingAfterMove() bool {
+	return figure.IsFigureCollidingAfterMove(e.Figure, e.Field, 0, 1)
+}
+
+// RestartGame сбрасывает игру.
+// Зерно увеличивается на единицу, чтобы новая игра получила другую, но воспроизводимую последовательность.
+func (e *Engine) RestartGame() {
+	cfg := e.Config
+	cfg.Seed++
+	restarted, err := NewEngine(cfg)
+	if err != nil {
+		log.Printf("не удалось перезапустить игру: %v", err)
+		return
+	}
+	*e = *restarted
+}
@@ -0,0 +1,330 @@
+package engine
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+	"tetris/internal/field"
+	"tetris/internal/figure"
+	"tetris/internal/models"
+	"time"
+)
+
+// frame - длительность кадра при 60 TPS
+const frame = time.Second / 60
+
+func TestMain(m *testing.M) {
+	// Движок пишет в журнал каждое движение фигуры
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// sequenceConfig возвращает настройки игры с заданной последовательностью фигур на поле шириной width
+func sequenceConfig(width int, shapes ...models.Shape) Config {
+	cfg := DefaultConfig()
+	cfg.Width = width
+	cfg.Randomizer = figure.RandomizerSequence
+	cfg.Sequence = shapes
+	return cfg
+}
+
+// newTestEngine создает движок или завершает тест с ошибкой
+func newTestEngine(t *testing.T, cfg Config) *Engine {
+	t.Helper()
+	e, err := NewEngine(cfg)
+	if err != nil {
+		t.Fatalf("не удалось создать движок: %v", err)
+	}
+	return e
+}
+
+// press нажимает клавиши на один кадр и отпускает их в следующем
+func press(e *Engine, in Input) {
+	e.Step(in, frame)
+	e.Step(Input{}, frame)
+}
+
+// randomActions - действия для случайного ввода: мгновенный сброс реже остальных, чтобы
+// фигуры успевали сдвинуться и повернуться, а перезапуск начинает новую игру после проигрыша
+var randomActions = []Input{
+	{}, {}, {Left: true}, {Right: true}, {Left: true, Down: true}, {Right: true, Down: true},
+	{Rotate: true}, {RotateCCW: true}, {Rotate180: true}, {Hold: true}, {Down: true}, {HardDrop: true}, {Restart: true},
+}
+
+// randomInputs возвращает n кадров ввода: действия выбираются случайно
+// и удерживаются по нескольку кадров, как у живого игрока
+func randomInputs(seed uint64, n int) []Input {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	inputs := make([]Input, 0, n)
+	for len(inputs) < n {
+		in := randomActions[rng.IntN(len(randomActions))]
+		for range min(1+rng.IntN(12), n-len(inputs)) {
+			inputs = append(inputs, in)
+		}
+	}
+	return inputs
+}
+
+// snapshotJSON возвращает снимок движка в JSON для сравнения состояний
+func snapshotJSON(t *testing.T, e *Engine) string {
+	t.Helper()
+	s, err := e.Snapshot()
+	if err != nil {
+		t.Fatalf("не удалось сохранить снимок: %v", err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("не удалось закодировать снимок: %v", err)
+	}
+	return string(data)
+}
+
+// TestScriptedClears проверяет фиксацию, очистку и счет на узком поле без задержек между фигурами:
+// горизонтальная I закрывает ряд целиком, две O рядом - два ряда, и оба раза поле становится пустым
+func TestScriptedClears(t *testing.T) {
+	cfg := sequenceConfig(4, models.ShapeI, models.ShapeO, models.ShapeO, models.ShapeT)
+	cfg.LineClearDelay, cfg.EntryDelay = 0, 0
+	e := newTestEngine(t, cfg)
+	bottom := e.Field.Rows() - 1
+
+	// Фигура появляется на строку ниже точки появления, и все три фигуры падают до дна на одно
+	// и то же расстояние: у I и O нижняя занятая строка матрицы - вторая
+	spawnY := field.BufferRows - 1
+	dist := bottom - 1 - spawnY
+	if e.Figure.Shape != models.ShapeI || e.Figure.Y != spawnY {
+		t.Fatalf("первая фигура %s на строке %d, ожидалась I на строке %d", e.Figure.Shape, e.Figure.Y, spawnY)
+	}
+	press(e, Input{HardDrop: true})
+	want := dist*hardDropScore + (oneLineScore+perfectClearScores[1])*e.Level
+	if e.Lines != 1 || e.Score != want || e.Pieces != 1 {
+		t.Fatalf("после I: линий %d, очков %d, фигур %d; ожидалось 1, %d, 1", e.Lines, e.Score, e.Pieces, want)
+	}
+	if e.LastClear != (Clear{Lines: 1, PerfectClear: true}) || e.Combo != 0 {
+		t.Fatalf("после I: очистка %+v, комбо %d", e.LastClear, e.Combo)
+	}
+	if !e.Field.IsEmpty() {
+		t.Fatal("после I поле не пустое")
+	}
+
+	// Первая O уходит к левой стене и не очищает линий: серия комбо прерывается
+	press(e, Input{Left: true})
+	press(e, Input{HardDrop: true})
+	want += dist * hardDropScore
+	if e.Score != want || e.Combo != -1 || e.Field.IsEmpty() {
+		t.Fatalf("после первой O: очков %d, комбо %d; ожидалось %d, -1", e.Score, e.Combo, want)
+	}
+	for _, x := range []int{0, 1} {
+		if !e.Field.IsOccupied(x, bottom) || e.Field.IsOccupied(x+2, bottom) {
+			t.Fatalf("первая O легла не к левой стене: %v", e.Field.Cells[bottom])
+		}
+	}
+
+	// Вторая O у правой стены закрывает два ряда
+	press(e, Input{Right: true})
+	press(e, Input{HardDrop: true})
+	want += dist*hardDropScore + (twoLineScore+perfectClearScores[2])*e.Level
+	if e.Lines != 3 || e.Score != want || e.Pieces != 3 {
+		t.Fatalf("после второй O: линий %d, очков %d, фигур %d; ожидалось 3, %d, 3", e.Lines, e.Score, e.Pieces, want)
+	}
+	if e.LastClear != (Clear{Lines: 2, PerfectClear: true}) || e.BackToBack {
+		t.Fatalf("после второй O: очистка %+v, Back-to-Back %v", e.LastClear, e.BackToBack)
+	}
+	wantStats := ClearStats{PerfectClears: 2}
+	wantStats.Lines[1], wantStats.Lines[2] = 1, 1
+	if e.Clears != wantStats {
+		t.Fatalf("статистика очисток %+v, ожидалась %+v", e.Clears, wantStats)
+	}
+	if !e.Field.IsEmpty() || e.Figure.Shape != models.ShapeT || e.GameOver {
+		t.Fatalf("после второй O: поле пустое %v, фигура %s, игра окончена %v", e.Field.IsEmpty(), e.Figure.Shape, e.GameOver)
+	}
+}
+
+// TestLineClearPhases проверяет, что линии засчитываются при фиксации, а ряды исчезают после
+// задержки очистки и следующая фигура появляется после задержки появления
+func TestLineClearPhases(t *testing.T) {
+	cfg := sequenceConfig(4, models.ShapeI, models.ShapeO)
+	e := newTestEngine(t, cfg)
+	bottom := e.Field.Rows() - 1
+
+	e.Step(Input{HardDrop: true}, frame)
+	if e.Phase != PhaseLineClear || e.Lines != 1 || !e.Field.IsRowFull(bottom) {
+		t.Fatalf("после сброса: этап %s, линий %d, ряд заполнен %v", e.Phase, e.Lines, e.Field.IsRowFull(bottom))
+	}
+	elapsed := time.Duration(0)
+	for e.Phase == PhaseLineClear {
+		e.Step(Input{}, frame)
+		elapsed += frame
+	}
+	if e.Phase != PhaseEntry || !e.Field.IsEmpty() || elapsed < cfg.LineClearDelay || elapsed >= cfg.LineClearDelay+frame {
+		t.Fatalf("после очистки: этап %s через %s, поле пустое %v", e.Phase, elapsed, e.Field.IsEmpty())
+	}
+	elapsed = 0
+	for e.Phase == PhaseEntry {
+		e.Step(Input{}, frame)
+		elapsed += frame
+	}
+	if e.Phase != PhaseFalling || e.Figure.Shape != models.ShapeO || elapsed < cfg.EntryDelay || elapsed >= cfg.EntryDelay+frame {
+		t.Fatalf("после задержки появления: этап %s через %s, фигура %s", e.Phase, elapsed, e.Figure.Shape)
+	}
+}
+
+// TestSnapshotRestore проверяет, что игра, сохраненная и восстановленная посередине,
+// продолжается так же, как игра без перерыва
+func TestSnapshotRestore(t *testing.T) {
+	cfg := sequenceConfig(field.DefaultWidth, models.ShapeT, models.ShapeS, models.ShapeI, models.ShapeL, models.ShapeO, models.ShapeZ, models.ShapeJ)
+	inputs := randomInputs(1, 3000)
+	for _, split := range []int{1, 500, 1234, 2500} {
+		whole := newTestEngine(t, cfg)
+		part := newTestEngine(t, cfg)
+		for _, in := range inputs[:split] {
+			whole.Step(in, frame)
+			part.Step(in, frame)
+		}
+
+		// Снимок проходит через JSON, как при записи в файл сохранения
+		s, err := part.Snapshot()
+		if err != nil {
+			t.Fatalf("кадр %d: не удалось сохранить снимок: %v", split, err)
+		}
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("кадр %d: не удалось закодировать снимок: %v", split, err)
+		}
+		var decoded Snapshot
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("кадр %d: не удалось прочитать снимок: %v", split, err)
+		}
+		restored, err := Restore(decoded)
+		if err != nil {
+			t.Fatalf("кадр %d: не удалось восстановить игру: %v", split, err)
+		}
+		if got, want := snapshotJSON(t, restored), snapshotJSON(t, whole); got != want {
+			t.Fatalf("кадр %d: восстановленная игра отличается от сохраненной", split)
+		}
+
+		for i, in := range inputs[split:] {
+			whole.Step(in, frame)
+			restored.Step(in, frame)
+			if restored.Score != whole.Score || restored.Pieces != whole.Pieces {
+				t.Fatalf("кадр %d после восстановления на кадре %d: очки %d/%d, фигур %d/%d",
+					split+i, split, restored.Score, whole.Score, restored.Pieces, whole.Pieces)
+			}
+		}
+		if got, want := snapshotJSON(t, restored), snapshotJSON(t, whole); got != want {
+			t.Fatalf("восстановление на кадре %d: итоговые состояния различаются", split)
+		}
+	}
+}
+
+// TestRestoreRejectsInvalid проверяет, что поврежденное сохранение возвращает ошибку, а не ломает движок
+func TestRestoreRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(s *Snapshot)
+	}{
+		{"figure shape", func(s *Snapshot) { s.Figure.Shape = 9 }},
+		{"figure rotation", func(s *Snapshot) { s.Figure.Rotation = 4 }},
+		{"figure cells", func(s *Snapshot) { s.Figure.Cells[3][3] = true }},
+		{"figure position", func(s *Snapshot) { s.Figure.Y = s.Field.Rows() - 1 }},
+		{"figure outside", func(s *Snapshot) { s.Figure.X = -100 }},
+		{"figure outside after lock", func(s *Snapshot) { s.Phase, s.Figure.Y = PhaseEntry, s.Field.Rows() }},
+		{"figure above field", func(s *Snapshot) { s.GameOver, s.Figure.Y = true, -4 }},
+		{"level", func(s *Snapshot) { s.Level = 0 }},
+		{"shift direction", func(s *Snapshot) { s.ShiftDirection = 2 }},
+		{"randomizer kind", func(s *Snapshot) { s.Randomizer.Kind = figure.RandomizerRandom }},
+		{"next", func(s *Snapshot) { s.Next[0] = -1 }},
+		{"hold", func(s *Snapshot) { s.Hold = 7 }},
+		{"last clear", func(s *Snapshot) { s.LastClear.Lines = 5 }},
+		{"last kick", func(s *Snapshot) { s.LastKick = figure.MaxKicks }},
+		{"last direction", func(s *Snapshot) { s.LastDirection = int(figure.RotateCCW) + 1 }},
+		{"bag shape", func(s *Snapshot) { s.Randomizer.Bag = []models.Shape{models.ShapeI, 12} }},
+		{"bag copies", func(s *Snapshot) { s.Randomizer.Bag = []models.Shape{models.ShapeT, models.ShapeT} }},
+	}
+	for _, tc := range tests {
+		e := newTestEngine(t, DefaultConfig())
+		s, err := e.Snapshot()
+		if err != nil {
+			t.Fatalf("не удалось сохранить снимок: %v", err)
+		}
+		if _, err := Restore(s); err != nil {
+			t.Fatalf("%s: целое сохранение не восстановилось: %v", tc.name, err)
+		}
+		tc.corrupt(&s)
+		if _, err := Restore(s); err == nil {
+			t.Errorf("%s: поврежденное сохранение восстановлено без ошибки", tc.name)
+		}
+	}
+
+	// Последовательность фигур проверяется и в настройках, и в состоянии генератора
+	e := newTestEngine(t, sequenceConfig(field.DefaultWidth, models.ShapeT))
+	s, err := e.Snapshot()
+	if err != nil {
+		t.Fatalf("не удалось сохранить снимок: %v", err)
+	}
+	s.Randomizer.Sequence = []models.Shape{models.ShapeT, 7}
+	if _, err := Restore(s); err == nil {
+		t.Error("сохранение с неизвестной фигурой в последовательности восстановлено без ошибки")
+	}
+	if _, err := NewEngine(sequenceConfig(field.DefaultWidth, models.ShapeT, -2)); err == nil {
+		t.Error("игра с неизвестной фигурой в последовательности создана без ошибки")
+	}
+}
+
+// TestTSpinFifthKick проверяет, что пятый тест смещения делает T-Spin полным только при повороте на 90°:
+// у поворота на 180° своя таблица смещений, и под тем же номером в ней обычный сдвиг
+func TestTSpinFifthKick(t *testing.T) {
+	e := newTestEngine(t, sequenceConfig(field.DefaultWidth, models.ShapeT))
+	fig := figure.NewFigure(e.Field, models.ShapeT)
+	fig.X, fig.Y = 3, 30
+	e.Figure = fig
+	// Заняты оба задних угла и один передний: по правилу трех углов это T-Spin Mini
+	for _, c := range [][2]int{{3, 30}, {3, 32}, {5, 32}} {
+		e.Field.Set(c[0], c[1], field.CellGarbage)
+	}
+
+	tests := []struct {
+		dir  figure.RotationDirection
+		kick int
+		want TSpin
+	}{
+		{figure.RotateCW, 0, TSpinMini},
+		{figure.RotateCW, fullTSpinKick, TSpinFull},
+		{figure.RotateCCW, fullTSpinKick, TSpinFull},
+		{figure.Rotate180, fullTSpinKick, TSpinMini},
+	}
+	for _, tc := range tests {
+		e.lastMoveRotation, e.lastDirection, e.lastKick = true, tc.dir, tc.kick
+		if got := e.detectTSpin(); got != tc.want {
+			t.Errorf("поворот %d, тест смещения %d: T-Spin %d, ожидался %d", tc.dir, tc.kick, got, tc.want)
+		}
+	}
+}
+
+// TestGoalEndsWithoutSpawn проверяет, что по достижении цели спринта ряды убираются сразу,
+// а следующая фигура не появляется и не забирается из очереди
+func TestGoalEndsWithoutSpawn(t *testing.T) {
+	cfg := sequenceConfig(4, models.ShapeI, models.ShapeI, models.ShapeO, models.ShapeT)
+	cfg.Mode, cfg.LineGoal = ModeSprint, 2
+	cfg.LineClearDelay, cfg.EntryDelay = 0, 0
+	e := newTestEngine(t, cfg)
+	for e.Ready > 0 {
+		e.Step(Input{}, frame)
+	}
+
+	press(e, Input{HardDrop: true})
+	next := append([]models.Shape(nil), e.Next...)
+	press(e, Input{HardDrop: true})
+	if !e.GameOver || !e.Completed || e.Reason != ReasonGoal {
+		t.Fatalf("после второй I: конец игры %v, цель %v, причина %q", e.GameOver, e.Completed, e.Reason)
+	}
+	if e.Lines != 2 || e.Pieces != 2 || !e.Field.IsEmpty() || len(e.ClearingRows) > 0 {
+		t.Fatalf("после второй I: линий %d, фигур %d, очищаемые ряды %v", e.Lines, e.Pieces, e.ClearingRows)
+	}
+	if e.Figure.Shape != models.ShapeI || !slices.Equal(e.Next, next) {
+		t.Fatalf("после цели появилась фигура %s, очередь %v, ожидалась %v", e.Figure.Shape, e.Next, next)
+	}
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"sync"
+	"tetris/internal/field"
+	"tetris/internal/figure"
+	"tetris/internal/models"
+)
+
+// placement - положение фигуры после падения: клетки (x, y) относительно нижней строки фигуры.
+// Симметричные повороты S, Z, I и O дают одинаковое положение.
+type placement [4][2]int
+
+// finesseTable хранит минимальное количество нажатий для каждого положения каждой фигуры
+type finesseTable map[models.Shape]map[placement]int
+
+var (
+	finesseMu     sync.Mutex
+	finesseTables = map[int]finesseTable{} // Вычисляются один раз для каждой ширины поля: поле в таблице пустое
+)
+
+// minFinesse возвращает минимальное количество нажатий сдвига и поворота, за которое фигура
+// попадает в положение f на поле шириной width, и false, если с пустого поля это положение сверху недостижимо
+func minFinesse(f *models.Figure, width int) (int, bool) {
+	finesseMu.Lock()
+	table, ok := finesseTables[width]
+	if !ok {
+		table = buildFinesseTable(width)
+		finesseTables[width] = table
+	}
+	finesseMu.Unlock()
+	n, ok := table[f.Shape][placementOf(f)]
+	return n, ok
+}
+
+// placementOf возвращает положение фигуры без учета высоты, на которой она лежит
+func placementOf(f *models.Figure) placement {
+	var p placement
+	i, top := 0, -1
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			if !f.Cells[row][col] {
+				continue
+			}
+			if top < 0 {
+				top = row
+			}
+			p[i] = [2]int{f.X + col, row - top}
+			i++
+		}
+	}
+	return p
+}
+
+// buildFinesseTable перебирает в ширину все положения фигур на пустом поле шириной width.
+// Одно нажатие - это сдвиг на клетку, сдвиг до стены с автоповтором или поворот в любую сторону.
+func buildFinesseTable(width int) finesseTable {
+	type state struct {
+		x, y int
+		rot  models.Rotation
+	}
+	fld := field.NewField(width, field.DefaultHeight, field.BufferRows)
+	table := finesseTable{}
+	for shape := models.ShapeI; shape <= models.ShapeZ; shape++ {
+		start := *figure.NewFigure(fld, shape)
+
+		moves := map[placement]int{}
+		seen := map[state]bool{{start.X, start.Y, start.Rotation}: true}
+		queue := []models.Figure{start}
+		for cost := 0; len(queue) > 0; cost++ {
+			var next []models.Figure
+			for _, f := range queue {
+				landed := figure.Ghost(&f, fld)
+				if _, ok := moves[placementOf(&landed)]; !ok {
+					moves[placementOf(&landed)] = cost
+				}
+				for _, g := range finesseSteps(f, fld) {
+					st := state{g.X, g.Y, g.Rotation}
+					if !seen[st] {
+						seen[st] = true
+						next = append(next, g)
+					}
+				}
+			}
+			queue = next
+		}
+		table[shape] = moves
+	}
+	return table
+}
+
+// finesseSteps возвращает положения, в которые фигура попадает за одно нажатие
+func finesseSteps(f models.Figure, fld *field.Field) []models.Figure {
+	var steps []models.Figure
+	for _, dx := range []int{-1, 1} {
+		if figure.IsFigureCollidingAfterMove(&f, fld, dx, 0) {
+			continue
+		}
+		tap := f
+		tap.X += dx
+		steps = append(steps, tap)
+		das := tap
+		for !figure.IsFigureCollidingAfterMove(&das, fld, dx, 0) {
+			das.X += dx
+		}
+		steps = append(steps, das)
+	}
+	for _, dir := range []figure.RotationDirection{figure.RotateCW, figure.RotateCCW, figure.Rotate180} {
+		if rotated, _, ok := figure.Rotated(&f, fld, dir); ok {
+			steps = append(steps, rotated)
+		}
+	}
+	return steps
+}
+
+// checkFinesse сравнивает нажатия, потраченные на фигуру, с минимальными для её положения.
+// Фигуры, опущенные ускоренным падением, не проверяются: их положение могло быть недостижимо сверху.
+func (e *Engine) checkFinesse() {
+	if e.pieceSoftDrop {
+		return
+	}
+	best, ok := minFinesse(e.Figure, e.Field.Width)
+	if ok && e.pieceInputs > best {
+		e.FinesseFaults += e.pieceInputs - best
+	}
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"tetris/internal/field"
+	"time"
+)
+
+const (
+	DefaultGarbageRows     = 10              // DefaultGarbageRows - Сколько мусорных рядов в начале режима Cheese по умолчанию
+	DefaultMessiness       = 30              // DefaultMessiness - Вероятность смены колонки дырки по умолчанию, %
+	DefaultGarbageInterval = 5 * time.Second // DefaultGarbageInterval - Как часто поднимается новый мусорный ряд по умолчанию
+	garbageHeadroom        = 2               // Сколько строк над начальным мусором остается свободными
+	garbageStreamConst     = 0x5851f42d4c957f2d
+)
+
+// CheeseRows - варианты количества мусорных рядов в режиме Cheese
+var CheeseRows = []int{5, 10, 18}
+
+// MaxGarbageRows возвращает, сколько мусорных рядов помещается на поле высотой height,
+// чтобы над мусором осталось место для появления фигуры
+func MaxGarbageRows(height int) int {
+	return height - garbageHeadroom
+}
+
+// garbage - генератор мусорных рядов: у каждого ряда одна дырка, которая с вероятностью
+// Messiness переходит в другую колонку
+type garbage struct {
+	pcg   *rand.PCG
+	rng   *rand.Rand
+	hole  int           // Колонка дырки в последнем добавленном ряду
+	timer time.Duration // Сколько прошло с последнего подъема мусора
+}
+
+// newGarbage создает генератор мусора с зерном игры. Поток отличается от генератора фигур,
+// поэтому мусор не влияет на последовательность фигур.
+func newGarbage(seed uint64, width int) *garbage {
+	pcg := rand.NewPCG(seed, seed^garbageStreamConst)
+	g := &garbage{pcg: pcg, rng: rand.New(pcg)}
+	g.hole = g.rng.IntN(width)
+	return g
+}
+
+// nextRow возвращает следующий мусорный ряд шириной width
+func (g *garbage) nextRow(width, messiness int) field.Row {
+	if g.rng.IntN(100) < messiness {
+		// Новая колонка всегда отличается от прежней, иначе беспорядок был бы меньше заданного
+		g.hole = (g.hole + 1 + g.rng.IntN(width-1)) % width
+	}
+	row := make(field.Row, width)
+	for x := range row {
+		if x != g.hole {
+			row[x] = field.CellGarbage
+		}
+	}
+	return row
+}
+
+// GarbageState - сохраняемое состояние генератора мусора
+type GarbageState struct {
+	RNG   []byte        `json:"rng"`   // Состояние источника случайных чисел
+	Hole  int           `json:"hole"`  // Колонка дырки в последнем ряду
+	Timer time.Duration `json:"timer"` // Сколько прошло с последнего подъема мусора
+}
+
+// state возвращает состояние генератора для сохранения игры
+func (g *garbage) state() (GarbageState, error) {
+	rng, err := g.pcg.MarshalBinary()
+	return GarbageState{RNG: rng, Hole: g.hole, Timer: g.timer}, err
+}
+
+// restoreGarbage восстанавливает генератор мусора из сохраненного состояния
+func restoreGarbage(st GarbageState, width int) (*garbage, error) {
+	if st.Hole < 0 || st.Hole >= width {
+		return nil, fmt.Errorf("неверная колонка дырки мусора: %d", st.Hole)
+	}
+	pcg := &rand.PCG{}
+	if err := pcg.UnmarshalBinary(st.RNG); err != nil {
+		return nil, fmt.Errorf("неверное состояние генератора мусора: %w", err)
+	}
+	return &garbage{pcg: pcg, rng: rand.New(pcg), hole: st.Hole, timer: st.Timer}, nil
+}
+
+// fillGarbage заполняет низ поля начальными мусорными рядами
+func (e *Engine) fillGarbage() {
+	for range e.Config.GarbageRows {
+		e.Field.InsertRow(e.garbage.nextRow(e.Field.Width, e.Config.Messiness))
+	}
+	e.GarbageLeft = e.Config.GarbageRows
+}
+
+// updateGarbage поднимает новый мусорный ряд каждые GarbageInterval
+func (e *Engine) updateGarbage(dt time.Duration) {
+	if e.Config.Mode != ModeCheese || e.Config.GarbageInterval <= 0 {
+		return
+	}
+	e.garbage.timer += dt
+	if e.garbage.timer < e.Config.GarbageInterval {
+		return
+	}
+	e.garbage.timer -= e.Config.GarbageInterval
+	e.riseGarbage()
+}
+
+// NextGarbage возвращает, через сколько поднимется следующий мусорный ряд, и false, если мусор не поднимается
+func (e *Engine) NextGarbage() (time.Duration, bool) {
+	if e.garbage == nil || e.Config.GarbageInterval <= 0 {
+		return 0, false
+	}
+	return e.Config.GarbageInterval - e.garbage.timer, true
+}
+
+// riseGarbage добавляет мусорный ряд снизу. Фигура поднимается вместе со стопкой, если иначе
+// она бы с ней пересеклась. Игра заканчивается, если блоки вытолкнуты за верх поля.
+func (e *Engine) riseGarbage() {
+	ok := e.Field.InsertRow(e.garbage.nextRow(e.Field.Width, e.Config.Messiness))
+	e.GarbageLeft++
+	log.Printf("снизу поднят мусорный ряд, осталось раскопать %d", e.GarbageLeft)
+	if ok && e.IsFigureColliding() {
+		e.Figure.Y--
+		e.lowestY--
+		ok = !e.IsFigureColliding()
+	}
+	if !ok {
+		e.endGame(ReasonTopOut)
+	}
+}
+
+// garbageRowsIn считает мусорные ряды среди очищаемых рядов rows. Мусор всегда лежит
+// в самом низу поля, поэтому это ряды среди нижних GarbageLeft.
+func (e *Engine) garbageRowsIn(rows []int) int {
+	n := 0
+	for _, y := range rows {
+		if y >= e.Field.Rows()-e.GarbageLeft {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"log"
+	"math"
+	"tetris/internal/figure"
+	"time"
+)
+
+const (
+	MinStartLevel   = 1  // MinStartLevel - Минимальный начальный уровень
+	MaxStartLevel   = 20 // MaxStartLevel - Максимальный начальный уровень
+	linesPerLevel   = 10 // Сколько линий нужно очистить для перехода на следующий уровень
+	maxGravityLevel = 20 // Начиная с этого уровня скорость падения больше не растет
+	maxGravityRows  = 20 // Предел падения за один кадр (20G)
+	splitLines      = 10 // Через сколько линий засекается промежуточное время
+)
+
+// SecondsPerRow возвращает время падения фигуры на одну строку по формуле гайдлайна
+func SecondsPerRow(level int) float64 {
+	n := float64(min(max(level, 1), maxGravityLevel) - 1)
+	// Явное преобразование запрещает компилятору объединять умножение и вычитание в FMA,
+	// иначе результат мог бы отличаться между архитектурами и ломать повторы
+	return math.Pow(0.8-float64(n*0.007), n)
+}
+
+// applyGravity накапливает дробное падение и опускает фигуру на целое число строк.
+// При ускоренном падении скорость умножается на SDF, а за каждую пройденную строку начисляются очки.
+func (e *Engine) applyGravity(dt time.Duration, softDrop bool) {
+	sdf := e.Config.Handling.SDF
+	if softDrop && sdf == 0 {
+		for e.moved(figure.MoveDown(e.Figure, e.Field)) {
+			e.Score += softDropScore
+		}
+		e.gravity = 0
+		return
+	}
+
+	rate := dt.Seconds() / SecondsPerRow(e.Level)
+	if softDrop {
+		rate *= float64(sdf)
+	}
+	e.gravity += float64(rate) // Без FMA, см. SecondsPerRow
+	rows := min(int(e.gravity), maxGravityRows)
+	e.gravity -= float64(int(e.gravity))
+	for range rows {
+		if !e.moved(figure.MoveDown(e.Figure, e.Field)) {
+			e.gravity = 0 // На опоре падение не копится
+			break
+		}
+		if softDrop {
+			e.Score += softDropScore
+		}
+	}
+}
+
+// addLines учитывает очищенные линии, запоминает время каждого десятка линий
+// и повышает уровень каждые linesPerLevel линий, если уровень в режиме растет
+func (e *Engine) addLines(n int) {
+	e.Lines += n
+	for len(e.Splits) < e.Lines/splitLines {
+		e.Splits = append(e.Splits, e.Time)
+	}
+	if e.Config.Mode.fixedLevel() {
+		return
+	}
+	level := e.Config.StartLevel + e.Lines/linesPerLevel
+	if level != e.Level {
+		e.Level = level
+		log.Printf("новый уровень: %d", e.Level)
+	}
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"fmt"
+	"tetris/internal/figure"
+	"time"
+)
+
+const (
+	defaultDAS = time.Millisecond * 167 // 10 кадров при 60 TPS
+	defaultARR = time.Millisecond * 33  // 2 кадра при 60 TPS
+	defaultSDF = 20
+)
+
+// Handling задает чувствительность управления фигурой, у каждого игрока своя
+type Handling struct {
+	DAS    time.Duration // Delayed Auto Shift - задержка перед автоповтором сдвига
+	ARR    time.Duration // Auto Repeat Rate - интервал автоповтора, 0 - сразу до упора
+	DASCut time.Duration // Пауза автоповтора после появления новой фигуры
+	SDF    int           // Soft Drop Factor - во сколько раз ускоряется падение, 0 - сразу до опоры
+}
+
+// DefaultHandling возвращает настройки управления по умолчанию
+func DefaultHandling() Handling {
+	return Handling{
+		DAS: defaultDAS,
+		ARR: defaultARR,
+		SDF: defaultSDF,
+	}
+}
+
+// Validate проверяет, что настройки управления допустимы
+func (h Handling) Validate() error {
+	if h.DAS < 0 || h.ARR < 0 || h.DASCut < 0 {
+		return fmt.Errorf("DAS, ARR и DAS cut не могут быть отрицательными: %s, %s, %s", h.DAS, h.ARR, h.DASCut)
+	}
+	if h.SDF < 0 {
+		return fmt.Errorf("SDF не может быть отрицательным: %d", h.SDF)
+	}
+	return nil
+}
+
+// shiftState хранит состояние автоповтора горизонтального сдвига
+type shiftState struct {
+	direction int           // Активное направление (0 - нет, -1 - влево, 1 - вправо)
+	held      time.Duration // Сколько удерживается активное направление
+	repeats   int           // Сколько автоповторов уже выполнено
+	cut       time.Duration // Оставшаяся пауза автоповтора после появления фигуры
+}
+
+// updateShift обрабатывает горизонтальный сдвиг: последнее нажатое направление главнее
+func (e *Engine) updateShift(in, prev Input, dt time.Duration) {
+	s := &e.shift
+	switch {
+	case in.Left && !prev.Left:
+		e.startShift(-1)
+	case in.Right && !prev.Right:
+		e.startShift(1)
+	case s.direction == -1 && !in.Left, s.direction == 1 && !in.Right:
+		// Активная клавиша отпущена - продолжаем в сторону, которая еще удерживается
+		s.direction = 0
+		if in.Left {
+			e.startShift(-1)
+		} else if in.Right {
+			e.startShift(1)
+		}
+	}
+	if s.direction == 0 {
+		return
+	}
+
+	s.held += dt
+	if s.cut > 0 {
+		s.cut -= dt
+		return
+	}
+	h := e.Config.Handling
+	if s.held < h.DAS {
+		return
+	}
+	if h.ARR == 0 {
+		for e.shiftOnce(s.direction) {
+		}
+		return
+	}
+	// Первый автоповтор - в момент срабатывания DAS, дальше раз в ARR
+	due := int((s.held-h.DAS)/h.ARR) + 1
+	for ; s.repeats < due; s.repeats++ {
+		if !e.shiftOnce(s.direction) {
+			s.repeats = due // Упор в стену не копит сдвиги на потом
+			break
+		}
+	}
+}
+
+// chargeShift копит DAS, пока фигуры нет на поле: удержанное направление сразу работает
+// с автоповтором у следующей фигуры, но сдвиги за время без фигуры не накапливаются
+func (e *Engine) chargeShift(in, prev Input, dt time.Duration) {
+	s := &e.shift
+	direction := s.direction
+	switch {
+	case in.Left && !prev.Left:
+		direction = -1
+	case in.Right && !prev.Right:
+		direction = 1
+	case s.direction == -1 && !in.Left, s.direction == 1 && !in.Right:
+		direction = 0
+		if in.Left {
+			direction = -1
+		} else if in.Right {
+			direction = 1
+		}
+	}
+	if direction != s.direction {
+		s.direction, s.held = direction, 0
+	}
+	s.repeats = 0
+	if s.direction != 0 {
+		s.held = min(s.held+dt, e.Config.Handling.DAS)
+	}
+}
+
+// startShift сдвигает фигуру сразу при нажатии и начинает отсчет DAS
+func (e *Engine) startShift(direction int) {
+	e.shift.direction = direction
+	e.shift.held = 0
+	e.shift.repeats = 0
+	e.shiftOnce(direction)
+}
+
+// shiftOnce сдвигает фигуру на одну клетку и сообщает, удалось ли это
+func (e *Engine) shiftOnce(direction int) bool {
+	if direction < 0 {
+		return e.moved(figure.MoveLeft(e.Figure, e.Field))
+	}
+	return e.moved(figure.MoveRight(e.Figure, e.Field))
+}
@@ -0,0 +1,40 @@
+package engine
+
+// Input описывает состояние логических клавиш в одном кадре
+type Input struct {
+	Left      bool // Left - сдвиг влево
+	Right     bool // Right - сдвиг вправо
+	Down      bool // Down - ускоренное падение
+	HardDrop  bool // HardDrop - мгновенный сброс с фиксацией
+	Rotate    bool // Rotate - поворот по часовой стрелке
+	RotateCCW bool // RotateCCW - поворот против часовой стрелки
+	Rotate180 bool // Rotate180 - поворот на 180°
+	Hold      bool // Hold - отложить текущую фигуру
+	Pause     bool // Pause - переключение паузы
+	Restart   bool // Restart - перезапуск после завершения игры
+}
+
+// inputFields перечисляет поля Input в порядке битов маски
+func (in *Input) inputFields() [10]*bool {
+	return [10]*bool{&in.Left, &in.Right, &in.Down, &in.HardDrop, &in.Rotate, &in.RotateCCW, &in.Rotate180, &in.Hold, &in.Pause, &in.Restart}
+}
+
+// Bits упаковывает состояние клавиш в битовую маску для компактной записи
+func (in Input) Bits() uint16 {
+	var bits uint16
+	for i, f := range in.inputFields() {
+		if *f {
+			bits |= 1 << i
+		}
+	}
+	return bits
+}
+
+// InputFromBits восстанавливает состояние клавиш из битовой маски
+func InputFromBits(bits uint16) Input {
+	var in Input
+	for i, f := range in.inputFields() {
+		*f = bits&(1<<i) != 0
+	}
+	return in
+}
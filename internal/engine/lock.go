@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"tetris/internal/figure"
+	"time"
+)
+
+// MaxLockResets - сколько раз движение может сбросить задержку фиксации в режиме LockExtended
+const MaxLockResets = 15
+
+// LockMode задает, как движения фигуры на опоре влияют на задержку фиксации
+type LockMode string
+
+const (
+	LockExtended LockMode = "extended" // LockExtended - Сдвиг или поворот сбрасывает задержку не более MaxLockResets раз
+	LockInfinity LockMode = "infinity" // LockInfinity - Сдвиг или поворот сбрасывает задержку без ограничений
+	LockClassic  LockMode = "classic"  // LockClassic - Задержка сбрасывается только при опускании фигуры ниже
+)
+
+// resetLockState сбрасывает задержку фиксации для только что появившейся фигуры
+func (e *Engine) resetLockState() {
+	e.lockTimer = 0
+	e.LockResets = 0
+	e.lowestY = e.Figure.Y
+}
+
+// onFigureMoved вызывается после успешного сдвига или поворота фигуры
+func (e *Engine) onFigureMoved() {
+	// Опускание на новую глубину всегда дает полную задержку заново
+	if e.Figure.Y > e.lowestY {
+		e.lowestY = e.Figure.Y
+		e.lockTimer = 0
+		e.LockResets = 0
+		return
+	}
+	if e.lockTimer == 0 {
+		return // Фигура не лежит на опоре, сбрасывать нечего
+	}
+	switch e.Config.LockMode {
+	case LockInfinity:
+		e.lockTimer = 0
+	case LockExtended:
+		if e.LockResets < MaxLockResets {
+			e.lockTimer = 0
+			e.LockResets++
+		}
+	}
+}
+
+// updateLock отсчитывает задержку фиксации и фиксирует фигуру, когда время вышло
+func (e *Engine) updateLock(dt time.Duration) {
+	if !e.IsFigureCollidingAfterMove() {
+		return // Фигура в воздухе - таймер стоит
+	}
+	e.lockTimer += dt
+	if e.lockTimer >= e.Config.LockDelay {
+		e.lockFigure()
+	}
+}
+
+// LockProgress возвращает долю истекшей задержки фиксации от 0 до 1
+func (e *Engine) LockProgress() float64 {
+	if e.GameOver || !e.IsFigureCollidingAfterMove() {
+		return 0
+	}
+	return min(float64(e.lockTimer)/float64(e.Config.LockDelay), 1)
+}
+
+// moved - вспомогательная обертка: отмечает успешное движение фигуры
+func (e *Engine) moved(ok bool) bool {
+	if ok {
+		e.onFigureMoved()
+		e.lastMoveRotation = false
+	}
+	return ok
+}
+
+// rotate поворачивает фигуру и запоминает поворот для задержки фиксации и определения T-Spin
+func (e *Engine) rotate(dir figure.RotationDirection) {
+	kick, ok := figure.Rotate(e.Figure, e.Field, dir)
+	if e.moved(ok) {
+		e.lastMoveRotation = true
+		e.lastKick, e.lastDirection = kick, dir
+	}
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"fmt"
+	"tetris/internal/field"
+	"time"
+)
+
+// Mode - режим игры, задающий её цель и условие окончания
+type Mode string
+
+const (
+	ModeMarathon Mode = "marathon" // ModeMarathon - Бесконечная игра на очки до заполнения поля
+	ModeSprint   Mode = "sprint"   // ModeSprint - Очистить заданное количество линий как можно быстрее
+	ModeUltra    Mode = "ultra"    // ModeUltra - Набрать как можно больше очков за отведенное время
+	ModeCheese   Mode = "cheese"   // ModeCheese - Раскопать мусорные ряды до самого дна
+
+	DefaultSprintGoal = 40              // DefaultSprintGoal - Цель режима Sprint по умолчанию
+	DefaultUltraTime  = 2 * time.Minute // DefaultUltraTime - Время режима Ultra по умолчанию
+	readyTime         = 2 * time.Second // Длительность отсчета "Ready" перед началом игры на время
+)
+
+// Modes - все режимы игры в порядке показа в меню
+var Modes = []Mode{ModeMarathon, ModeSprint, ModeUltra, ModeCheese}
+
+// SprintGoals - варианты цели режима Sprint в линиях
+var SprintGoals = []int{20, 40, 100}
+
+// UltraTimes - варианты длительности режима Ultra
+var UltraTimes = []time.Duration{2 * time.Minute, 3 * time.Minute}
+
+// Title возвращает название режима для меню
+func (m Mode) Title() string {
+	switch m {
+	case ModeMarathon:
+		return "Marathon"
+	case ModeSprint:
+		return "Sprint"
+	case ModeUltra:
+		return "Ultra"
+	case ModeCheese:
+		return "Cheese"
+	}
+	return string(m)
+}
+
+// fixedLevel сообщает, что уровень в режиме не растет: все режимы, кроме марафона, идут на скорости первого уровня
+func (m Mode) fixedLevel() bool {
+	return m != ModeMarathon
+}
+
+// Race сообщает, что в режиме соревнуются во времени достижения цели, а не в очках
+func (m Mode) Race() bool {
+	return m == ModeSprint || m == ModeCheese
+}
+
+// ReadyTime возвращает длительность отсчета "Ready" перед началом игры.
+// Он есть только в Ultra, где каждая секунда на счету.
+func (m Mode) ReadyTime() time.Duration {
+	if m == ModeUltra {
+		return readyTime
+	}
+	return 0
+}
+
+// startLevel возвращает уровень, с которого начинается игра в режиме
+func (m Mode) startLevel(level int) int {
+	if m.fixedLevel() {
+		return MinStartLevel
+	}
+	return level
+}
+
+// validateMode проверяет, что режим известен
+func validateMode(m Mode) error {
+	for _, known := range Modes {
+		if m == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("неизвестный режим игры: %q", m)
+}
+
+// Title возвращает название режима вместе с его целью, например "Sprint 40L"
+func (c Config) Title() string {
+	switch c.Mode {
+	case ModeSprint:
+		return fmt.Sprintf("%s %dL", c.Mode.Title(), c.LineGoal)
+	case ModeUltra:
+		return fmt.Sprintf("%s %d min", c.Mode.Title(), int(c.TimeLimit.Minutes()))
+	case ModeCheese:
+		return fmt.Sprintf("%s %d rows", c.Mode.Title(), c.GarbageRows)
+	}
+	return c.Mode.Title()
+}
+
+// Category возвращает ключ таблицы рекордов: результаты с разной целью не сравниваются.
+// Задержки очистки линий и появления фигуры влияют на время и темп игры, поэтому результаты
+// с задержками не по умолчанию хранятся в отдельной категории.
+func (c Config) Category() string {
+	category := string(c.Mode)
+	switch c.Mode {
+	case ModeSprint:
+		category = fmt.Sprintf("%s%d", c.Mode, c.LineGoal)
+	case ModeUltra:
+		category = fmt.Sprintf("%s%dm", c.Mode, int(c.TimeLimit.Minutes()))
+	case ModeCheese:
+		category = fmt.Sprintf("%s%d", c.Mode, c.GarbageRows)
+	}
+	if c.LineClearDelay != defaultLineClearDelay || c.EntryDelay != defaultEntryDelay {
+		category += fmt.Sprintf("-lc%d-are%d", c.LineClearDelay.Milliseconds(), c.EntryDelay.Milliseconds())
+	}
+	return category
+}
+
+// Ranked сообщает, что игра идет на стандартном поле и её результат можно сравнивать с рекордами
+func (c Config) Ranked() bool {
+	return c.Width == field.DefaultWidth && c.Height == field.DefaultHeight
+}
+
+// goalReached проверяет, достигнута ли цель режима
+func (e *Engine) goalReached() bool {
+	switch e.Config.Mode {
+	case ModeSprint:
+		return e.Lines >= e.Config.LineGoal
+	case ModeCheese:
+		return e.GarbageLeft == 0
+	}
+	return false
+}
+
+// timeUp проверяет, истекло ли время игры в режиме Ultra
+func (e *Engine) timeUp() bool {
+	return e.Config.Mode == ModeUltra && e.Time >= e.Config.TimeLimit
+}
+
+// Remaining возвращает, сколько времени осталось до конца игры на время
+func (e *Engine) Remaining() time.Duration {
+	return max(e.Config.TimeLimit-e.Time, 0)
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	defaultLineClearDelay = time.Millisecond * 300
+	defaultEntryDelay     = time.Millisecond * 100
+	MaxLineClearDelay     = time.Second // MaxLineClearDelay - Максимальная задержка очистки линий
+	MaxEntryDelay         = time.Second // MaxEntryDelay - Максимальная задержка появления фигуры (ARE)
+)
+
+// Phase - этап между появлением фигур
+type Phase string
+
+const (
+	PhaseFalling   Phase = "falling"    // PhaseFalling - Фигура падает и управляется игроком
+	PhaseLineClear Phase = "line_clear" // PhaseLineClear - Заполненные ряды исчезают, поле еще не сдвинуто
+	PhaseEntry     Phase = "entry"      // PhaseEntry - Задержка перед появлением следующей фигуры (ARE)
+)
+
+// startPhase переводит игру на этап p длительностью d
+func (e *Engine) startPhase(p Phase, d time.Duration) {
+	e.Phase, e.PhaseTimer = p, d
+}
+
+// updatePhase отсчитывает очистку линий и задержку появления и переходит к следующему этапу
+func (e *Engine) updatePhase(dt time.Duration) {
+	e.PhaseTimer -= dt
+	if e.PhaseTimer > 0 {
+		return
+	}
+	switch e.Phase {
+	case PhaseLineClear:
+		e.collapseRows()
+	case PhaseEntry:
+		e.spawnFigure(e.takeNext())
+	}
+}
+
+// PhaseProgress возвращает долю прошедшего времени текущего этапа от 0 до 1
+func (e *Engine) PhaseProgress() float64 {
+	var total time.Duration
+	switch e.Phase {
+	case PhaseLineClear:
+		total = e.Config.LineClearDelay
+	case PhaseEntry:
+		total = e.Config.EntryDelay
+	}
+	if total <= 0 {
+		return 0
+	}
+	return min(1-float64(e.PhaseTimer)/float64(total), 1)
+}
+
+// collapseRows удаляет очищаемые ряды и переходит к задержке появления следующей фигуры.
+// Очки и линии за эти ряды уже начислены при фиксации.
+func (e *Engine) collapseRows() {
+	e.Field.ClearRows(e.ClearingRows)
+	if len(e.ClearingRows) > 0 {
+		log.Printf("очищены ряды: %v", e.ClearingRows)
+	}
+	e.ClearingRows = nil
+
+	// Обмен с удержанием снова доступен для следующей фигуры
+	e.HoldUsed = false
+	if e.Config.EntryDelay > 0 {
+		e.startPhase(PhaseEntry, e.Config.EntryDelay)
+		return
+	}
+	e.spawnFigure(e.takeNext())
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"log"
+	"strings"
+)
+
+const (
+	oneLineScore   = 100
+	twoLineScore   = 300
+	threeLineScore = 700
+	fourLineScore  = 1500
+	softDropScore  = 1  // Очки за каждую клетку ускоренного падения
+	hardDropScore  = 2  // Очки за каждую клетку мгновенного сброса
+	comboScore     = 50 // Очки за каждую ступень комбо
+	//Perfect Clear
+	b2bPerfectClearTetrisScore = 3200 // Очки за Perfect Clear тетрисом подряд за сложной очисткой
+)
+
+// lineScores - очки за обычную очистку 0-4 линий
+var lineScores = [...]int{0, oneLineScore, twoLineScore, threeLineScore, fourLineScore}
+
+// tSpinScores - очки гайдлайна за T-Spin с очисткой 0-3 линий
+var tSpinScores = [...]int{400, 800, 1200, 1600}
+
+// tSpinMiniScores - очки гайдлайна за T-Spin Mini с очисткой 0-2 линий
+var tSpinMiniScores = [...]int{100, 200, 400}
+
+// perfectClearScores - дополнительные очки за полностью очищенное поле после очистки 0-4 линий
+var perfectClearScores = [...]int{0, 800, 1200, 1800, 2000}
+
+// Clear описывает результат фиксации одной фигуры
+type Clear struct {
+	Lines        int   // Сколько линий очищено
+	TSpin        TSpin // Был ли это T-Spin
+	Combo        int   // Номер очистки в серии подряд (0 - первая очистка, без бонуса)
+	BackToBack   bool  // Сложная очистка сразу после другой сложной
+	PerfectClear bool  // Поле стало полностью пустым
+}
+
+// ClearStats - сколько раз игрок делал очистки каждого вида
+type ClearStats struct {
+	Lines         [5]int `json:"lines"`          // Обычные очистки по числу линий, индекс 0 не используется
+	TSpins        [4]int `json:"t_spins"`        // T-Spin с очисткой 0-3 линий
+	TSpinMinis    [3]int `json:"t_spin_minis"`   // T-Spin Mini с очисткой 0-2 линий
+	BackToBacks   int    `json:"back_to_backs"`  // Сложные очистки подряд за сложными
+	PerfectClears int    `json:"perfect_clears"` // Очистки всего поля
+	MaxCombo      int    `json:"max_combo"`      // Самая длинная серия очисток подряд
+}
+
+// add учитывает очистку c
+func (s *ClearStats) add(c Clear) {
+	switch c.TSpin {
+	case TSpinFull:
+		s.TSpins[min(c.Lines, len(s.TSpins)-1)]++
+	case TSpinMini:
+		s.TSpinMinis[min(c.Lines, len(s.TSpinMinis)-1)]++
+	default:
+		s.Lines[c.Lines]++
+	}
+	if c.BackToBack {
+		s.BackToBacks++
+	}
+	if c.PerfectClear {
+		s.PerfectClears++
+	}
+	s.MaxCombo = max(s.MaxCombo, c.Combo)
+}
+
+// String возвращает название очистки для подписи на экране, например "T-Spin Double"
+func (c Clear) String() string {
+	lines := [...]string{"", "Single", "Double", "Triple", "Tetris"}[c.Lines]
+	name := lines
+	switch c.TSpin {
+	case TSpinFull:
+		name = strings.TrimSpace("T-Spin " + lines)
+	case TSpinMini:
+		name = strings.TrimSpace("T-Spin Mini " + lines)
+	}
+	if c.BackToBack {
+		name = "B2B " + name
+	}
+	return name
+}
+
+// Difficult сообщает, считается ли очистка сложной (Tetris или T-Spin с линиями) для Back-to-Back
+func (c Clear) Difficult() bool {
+	return c.Lines == 4 || (c.TSpin != TSpinNone && c.Lines > 0)
+}
+
+// basePoints возвращает очки за очистку без множителя уровня
+func (c Clear) basePoints() int {
+	switch c.TSpin {
+	case TSpinFull:
+		return tSpinScores[min(c.Lines, len(tSpinScores)-1)]
+	case TSpinMini:
+		return tSpinMiniScores[min(c.Lines, len(tSpinMiniScores)-1)]
+	default:
+		return lineScores[c.Lines]
+	}
+}
+
+// scoreClear начисляет очки за результат фиксации с учетом комбо, Back-to-Back и Perfect Clear
+func (e *Engine) scoreClear(c Clear) {
+	if c.Lines == 0 {
+		// Фиксация без очистки прерывает комбо, но не Back-to-Back
+		e.Combo = -1
+		if c.TSpin != TSpinNone {
+			points := c.basePoints() * e.Level
+			e.Score += points
+			e.rememberClear(c, points)
+		}
+		return
+	}
+
+	e.Combo++
+	c.Combo = e.Combo
+	c.BackToBack = c.Difficult() && e.BackToBack
+
+	// Очки за линии умножаются на уровень, на котором они очищены
+	points := c.basePoints() * e.Level
+	if c.BackToBack {
+		points = points * 3 / 2
+	}
+	points += comboScore * c.Combo * e.Level
+	if c.PerfectClear {
+		bonus := perfectClearScores[c.Lines]
+		if c.BackToBack && c.Lines == 4 {
+			bonus = b2bPerfectClearTetrisScore
+		}
+		points += bonus * e.Level
+	}
+	e.Score += points
+
+	// Обычная очистка прерывает серию сложных, сложная - продолжает
+	e.BackToBack = c.Difficult()
+	e.rememberClear(c, points)
+	e.addLines(c.Lines)
+}
+
+// perfectClear проверяет, что после удаления заполненных рядов поле станет полностью пустым
+func (e *Engine) perfectClear() bool {
+	board := e.Field.Bitboard()
+	return board.ClearFull() > 0 && board.IsEmpty()
+}
+
+// rememberClear сохраняет результат для подписи на экране
+func (e *Engine) rememberClear(c Clear, points int) {
+	e.Clears.add(c)
+	e.LastClear = c
+	e.SinceLastClear = 0
+	log.Printf("%s: +%d очков", c, points)
+}
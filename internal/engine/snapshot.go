@@ -0,0 +1,275 @@
+package engine
+
+import (
+	"fmt"
+	"tetris/internal/field"
+	"tetris/internal/figure"
+	"tetris/internal/models"
+	"time"
+)
+
+// Snapshot - полное состояние игры для сохранения и продолжения с того же места
+type Snapshot struct {
+	EngineVersion  int                    `json:"engine_version"`
+	Config         Config                 `json:"config"`
+	Field          *field.Field           `json:"field"`
+	Figure         models.Figure          `json:"figure"`
+	Randomizer     figure.RandomizerState `json:"randomizer"`
+	Next           []models.Shape         `json:"next"`
+	Hold           models.Shape           `json:"hold"`
+	HasHold        bool                   `json:"has_hold"`
+	HoldUsed       bool                   `json:"hold_used"`
+	GameOver       bool                   `json:"game_over"`
+	Completed      bool                   `json:"completed"`
+	Reason         Reason                 `json:"reason,omitempty"`
+	Ready          time.Duration          `json:"ready"`
+	Phase          Phase                  `json:"phase"`
+	PhaseTimer     time.Duration          `json:"phase_timer"`
+	ClearingRows   []int                  `json:"clearing_rows,omitempty"`
+	Paused         bool                   `json:"paused"`
+	Score          int                    `json:"score"`
+	Level          int                    `json:"level"`
+	Lines          int                    `json:"lines"`
+	Combo          int                    `json:"combo"`
+	BackToBack     bool                   `json:"back_to_back"`
+	Time           time.Duration          `json:"time"`
+	Pieces         int                    `json:"pieces"`
+	Keys           int                    `json:"keys"`
+	FinesseFaults  int                    `json:"finesse_faults"`
+	Splits         []time.Duration        `json:"splits"`
+	Clears         ClearStats             `json:"clears"`
+	GarbageLeft    int                    `json:"garbage_left"`
+	Garbage        *GarbageState          `json:"garbage,omitempty"`
+	PieceInputs    int                    `json:"piece_inputs"`
+	PieceSoftDrop  bool                   `json:"piece_soft_drop"`
+	LastClear      Clear                  `json:"last_clear"`
+	SinceLastClear time.Duration          `json:"since_last_clear"`
+	Gravity        float64                `json:"gravity"`
+	ShiftDirection int                    `json:"shift_direction"`
+	ShiftHeld      time.Duration          `json:"shift_held"`
+	ShiftRepeats   int                    `json:"shift_repeats"`
+	ShiftCut       time.Duration          `json:"shift_cut"`
+	LockResets     int                    `json:"lock_resets"`
+	LockTimer      time.Duration          `json:"lock_timer"`
+	LowestY        int                    `json:"lowest_y"`
+	LastRotation   bool                   `json:"last_rotation"`
+	LastKick       int                    `json:"last_kick"`
+	LastDirection  int                    `json:"last_direction"`
+	PrevInput      uint16                 `json:"prev_input"`
+}
+
+// Snapshot сохраняет текущее состояние игры
+func (e *Engine) Snapshot() (Snapshot, error) {
+	rnd, err := e.Randomizer.State()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("не удалось сохранить генератор фигур: %w", err)
+	}
+	var garbage *GarbageState
+	if e.garbage != nil {
+		st, err := e.garbage.state()
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("не удалось сохранить генератор мусора: %w", err)
+		}
+		garbage = &st
+	}
+	return Snapshot{
+		EngineVersion:  Version,
+		Config:         e.Config,
+		Field:          e.Field.Clone(),
+		Figure:         *e.Figure,
+		Randomizer:     rnd,
+		Next:           append([]models.Shape(nil), e.Next...),
+		Hold:           e.Hold,
+		HasHold:        e.HasHold,
+		HoldUsed:       e.HoldUsed,
+		GameOver:       e.GameOver,
+		Completed:      e.Completed,
+		Reason:         e.Reason,
+		Ready:          e.Ready,
+		Phase:          e.Phase,
+		PhaseTimer:     e.PhaseTimer,
+		ClearingRows:   append([]int(nil), e.ClearingRows...),
+		Paused:         e.Paused,
+		Score:          e.Score,
+		Level:          e.Level,
+		Lines:          e.Lines,
+		Combo:          e.Combo,
+		BackToBack:     e.BackToBack,
+		Time:           e.Time,
+		Pieces:         e.Pieces,
+		Keys:           e.Keys,
+		FinesseFaults:  e.FinesseFaults,
+		Splits:         append([]time.Duration(nil), e.Splits...),
+		Clears:         e.Clears,
+		GarbageLeft:    e.GarbageLeft,
+		Garbage:        garbage,
+		PieceInputs:    e.pieceInputs,
+		PieceSoftDrop:  e.pieceSoftDrop,
+		LastClear:      e.LastClear,
+		SinceLastClear: e.SinceLastClear,
+		Gravity:        e.gravity,
+		ShiftDirection: e.shift.direction,
+		ShiftHeld:      e.shift.held,
+		ShiftRepeats:   e.shift.repeats,
+		ShiftCut:       e.shift.cut,
+		LockResets:     e.LockResets,
+		LockTimer:      e.lockTimer,
+		LowestY:        e.lowestY,
+		LastRotation:   e.lastMoveRotation,
+		LastKick:       e.lastKick,
+		LastDirection:  int(e.lastDirection),
+		PrevInput:      e.prevInput.Bits(),
+	}, nil
+}
+
+// Restore создает движок из сохраненного состояния
+func Restore(s Snapshot) (*Engine, error) {
+	if s.EngineVersion != Version {
+		return nil, fmt.Errorf("сохранение сделано версией движка %d, текущая версия %d", s.EngineVersion, Version)
+	}
+	if s.Config.Mode == "" {
+		s.Config.Mode = ModeMarathon
+	}
+	if err := s.Config.Validate(); err != nil {
+		return nil, err
+	}
+	if s.Field == nil || len(s.Next) != s.Config.NextCount {
+		return nil, fmt.Errorf("неполное сохранение")
+	}
+	if !s.Field.Valid() || s.Field.Width != s.Config.Width || s.Field.Height != s.Config.Height {
+		return nil, fmt.Errorf("размер поля в сохранении не совпадает с настройками игры")
+	}
+	if err := validatePhase(s); err != nil {
+		return nil, err
+	}
+	if err := validatePieces(s); err != nil {
+		return nil, err
+	}
+	// Уровень множит очки и выбирает скорость падения, направление автоповтора - сдвиг фигуры
+	if s.Level < MinStartLevel {
+		return nil, fmt.Errorf("неверный уровень в сохранении: %d", s.Level)
+	}
+	if s.ShiftDirection < -1 || s.ShiftDirection > 1 {
+		return nil, fmt.Errorf("неверное направление автоповтора в сохранении: %d", s.ShiftDirection)
+	}
+	if s.Randomizer.Kind != s.Config.Randomizer {
+		return nil, fmt.Errorf("генератор фигур %q в сохранении не совпадает с настройками игры %q", s.Randomizer.Kind, s.Config.Randomizer)
+	}
+	rnd, err := figure.RestoreRandomizer(s.Randomizer)
+	if err != nil {
+		return nil, err
+	}
+	var garbage *garbage
+	if s.Config.Mode == ModeCheese {
+		if s.Garbage == nil {
+			return nil, fmt.Errorf("неполное сохранение: нет состояния генератора мусора")
+		}
+		if garbage, err = restoreGarbage(*s.Garbage, s.Config.Width); err != nil {
+			return nil, err
+		}
+	}
+	fig := s.Figure
+	return &Engine{
+		Config:           s.Config,
+		Field:            s.Field,
+		Figure:           &fig,
+		Randomizer:       rnd,
+		Next:             s.Next,
+		Hold:             s.Hold,
+		HasHold:          s.HasHold,
+		HoldUsed:         s.HoldUsed,
+		GameOver:         s.GameOver,
+		Completed:        s.Completed,
+		Reason:           s.Reason,
+		Ready:            s.Ready,
+		Phase:            s.Phase,
+		PhaseTimer:       s.PhaseTimer,
+		ClearingRows:     s.ClearingRows,
+		Paused:           s.Paused,
+		Score:            s.Score,
+		Level:            s.Level,
+		Lines:            s.Lines,
+		Combo:            s.Combo,
+		BackToBack:       s.BackToBack,
+		Time:             s.Time,
+		Pieces:           s.Pieces,
+		Keys:             s.Keys,
+		FinesseFaults:    s.FinesseFaults,
+		Splits:           s.Splits,
+		Clears:           s.Clears,
+		GarbageLeft:      s.GarbageLeft,
+		garbage:          garbage,
+		pieceInputs:      s.PieceInputs,
+		pieceSoftDrop:    s.PieceSoftDrop,
+		LastClear:        s.LastClear,
+		SinceLastClear:   s.SinceLastClear,
+		gravity:          s.Gravity,
+		shift:            shiftState{direction: s.ShiftDirection, held: s.ShiftHeld, repeats: s.ShiftRepeats, cut: s.ShiftCut},
+		LockResets:       s.LockResets,
+		lockTimer:        s.LockTimer,
+		lowestY:          s.LowestY,
+		lastMoveRotation: s.LastRotation,
+		lastKick:         s.LastKick,
+		lastDirection:    figure.RotationDirection(s.LastDirection),
+		prevInput:        InputFromBits(s.PrevInput),
+	}, nil
+}
+
+// validatePieces проверяет фигуры в сохранении: текущую фигуру, очередь, слот удержания,
+// последнюю очистку и тест смещения последнего поворота
+func validatePieces(s Snapshot) error {
+	if err := figure.Validate(&s.Figure); err != nil {
+		return err
+	}
+	// На любом этапе фигура лежит внутри поля: на пустом поле того же размера она сталкивается только с краями
+	mask := figure.Mask(&s.Figure)
+	if field.NewField(s.Field.Width, s.Field.Height, s.Field.Buffer).Collides(mask, s.Figure.X, s.Figure.Y) {
+		return fmt.Errorf("фигура %s в клетке (%d, %d) за границами поля", s.Figure.Shape, s.Figure.X, s.Figure.Y)
+	}
+	// Во время падения фигура не может пересекаться с полем. На других этапах и после
+	// окончания игры она уже зафиксирована или не поместилась при появлении.
+	if s.Phase == PhaseFalling && !s.GameOver && s.Field.Collides(mask, s.Figure.X, s.Figure.Y) {
+		return fmt.Errorf("фигура %s в клетке (%d, %d) пересекается с полем", s.Figure.Shape, s.Figure.X, s.Figure.Y)
+	}
+	for _, shape := range s.Next {
+		if !shape.Valid() {
+			return fmt.Errorf("неверная фигура %d в очереди", shape)
+		}
+	}
+	if !s.Hold.Valid() {
+		return fmt.Errorf("неверная отложенная фигура %d", s.Hold)
+	}
+	if s.LastClear.Lines < 0 || s.LastClear.Lines >= len(lineScores) || s.LastClear.TSpin < TSpinNone || s.LastClear.TSpin > TSpinFull {
+		return fmt.Errorf("неверная последняя очистка: %d линий, T-Spin %d", s.LastClear.Lines, s.LastClear.TSpin)
+	}
+	if s.LastKick < 0 || s.LastKick >= figure.MaxKicks {
+		return fmt.Errorf("неверный номер теста смещения: %d", s.LastKick)
+	}
+	// До первого поворота направление нулевое
+	if s.LastDirection < 0 || s.LastDirection > int(figure.RotateCCW) {
+		return fmt.Errorf("неверное направление поворота: %d", s.LastDirection)
+	}
+	return nil
+}
+
+// validatePhase проверяет этап игры в сохранении: очищаемые ряды должны быть заполнены и идти сверху вниз
+func validatePhase(s Snapshot) error {
+	switch s.Phase {
+	case PhaseFalling, PhaseEntry:
+		if len(s.ClearingRows) > 0 {
+			return fmt.Errorf("очищаемые ряды в сохранении вне этапа очистки линий")
+		}
+	case PhaseLineClear:
+		if len(s.ClearingRows) == 0 {
+			return fmt.Errorf("неполное сохранение: нет очищаемых рядов")
+		}
+		for i, y := range s.ClearingRows {
+			if (i > 0 && y <= s.ClearingRows[i-1]) || !s.Field.IsRowFull(y) {
+				return fmt.Errorf("неверный очищаемый ряд %d в сохранении", y)
+			}
+		}
+	default:
+		return fmt.Errorf("неизвестный этап игры в сохранении: %q", s.Phase)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package engine
+
+import "math/bits"
+
+var (
+	// keyMask - игровые клавиши, нажатия которых считаются для KPP
+	keyMask = Input{Left: true, Right: true, Down: true, HardDrop: true, Rotate: true, RotateCCW: true, Rotate180: true, Hold: true}.Bits()
+	// finesseMask - клавиши, нажатия которых сравниваются с оптимальной техникой
+	finesseMask = Input{Left: true, Right: true, Rotate: true, RotateCCW: true, Rotate180: true}.Bits()
+)
+
+// countInputs учитывает нажатия клавиш в кадре
+func (e *Engine) countInputs(in, prev Input) {
+	pressed := in.Bits() &^ prev.Bits()
+	e.Keys += bits.OnesCount16(pressed & keyMask)
+	e.pieceInputs += bits.OnesCount16(pressed & finesseMask)
+	if in.Down {
+		e.pieceSoftDrop = true
+	}
+}
+
+// PPS возвращает среднее количество фигур в секунду
+func (e *Engine) PPS() float64 {
+	if e.Time <= 0 {
+		return 0
+	}
+	return float64(e.Pieces) / e.Time.Seconds()
+}
+
+// KPP возвращает среднее количество нажатий на фигуру
+func (e *Engine) KPP() float64 {
+	if e.Pieces == 0 {
+		return 0
+	}
+	return float64(e.Keys) / float64(e.Pieces)
+}
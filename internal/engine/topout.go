@@ -0,0 +1,50 @@
+package engine
+
+import "log"
+
+// Reason - причина окончания игры
+type Reason string
+
+const (
+	ReasonBlockOut Reason = "block_out" // ReasonBlockOut - Новой фигуре нет места в точке появления
+	ReasonLockOut  Reason = "lock_out"  // ReasonLockOut - Фигура зафиксирована целиком выше видимой части поля
+	ReasonTopOut   Reason = "top_out"   // ReasonTopOut - Мусор вытолкнул блоки за верх поля
+	ReasonGoal     Reason = "goal"      // ReasonGoal - Цель режима достигнута
+	ReasonTimeUp   Reason = "time_up"   // ReasonTimeUp - Время режима истекло
+)
+
+// Title возвращает причину окончания игры для экрана
+func (r Reason) Title() string {
+	switch r {
+	case ReasonBlockOut:
+		return "Block out"
+	case ReasonLockOut:
+		return "Lock out"
+	case ReasonTopOut:
+		return "Top out"
+	case ReasonGoal:
+		return "Finished"
+	case ReasonTimeUp:
+		return "Time up"
+	}
+	return "Game over"
+}
+
+// endGame заканчивает игру по причине r. Цель и истекшее время - это завершение режима, а не проигрыш.
+func (e *Engine) endGame(r Reason) {
+	e.GameOver, e.Reason = true, r
+	e.Completed = r == ReasonGoal || r == ReasonTimeUp
+	log.Printf("игра окончена (%s), счет: %d, время: %s", r.Title(), e.Score, e.Time)
+}
+
+// lockedOut проверяет, что фигура целиком лежит в скрытых строках над видимой частью поля
+func (e *Engine) lockedOut() bool {
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			if e.Figure.Cells[row][col] && e.Figure.Y+row >= e.Field.Buffer {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"tetris/internal/figure"
+	"tetris/internal/models"
+)
+
+// fullTSpinKick - номер пятого теста смещения SRS при повороте на 90° (например, (-1,-2) для 0->R),
+// после которого T-Spin всегда полный. У поворота на 180° своя таблица, и её пятый тест - обычный сдвиг.
+const fullTSpinKick = 4
+
+// TSpin описывает вид T-Spin
+type TSpin int
+
+const (
+	TSpinNone TSpin = iota // TSpinNone - Не T-Spin
+	TSpinMini              // TSpinMini - T-Spin Mini
+	TSpinFull              // TSpinFull - Полный T-Spin
+)
+
+// tCorner - угол квадрата 3x3 вокруг центра фигуры T
+type tCorner struct {
+	col, row int
+}
+
+var (
+	topLeft     = tCorner{0, 0}
+	topRight    = tCorner{2, 0}
+	bottomLeft  = tCorner{0, 2}
+	bottomRight = tCorner{2, 2}
+)
+
+// frontCorners - углы со стороны, куда направлен выступ T, для каждого состояния поворота
+var frontCorners = [4][2]tCorner{
+	models.Rotation0: {topLeft, topRight},
+	models.RotationR: {topRight, bottomRight},
+	models.Rotation2: {bottomLeft, bottomRight},
+	models.RotationL: {topLeft, bottomLeft},
+}
+
+// detectTSpin определяет T-Spin по правилу трех углов для фиксируемой фигуры
+func (e *Engine) detectTSpin() TSpin {
+	f := e.Figure
+	if f.Shape != models.ShapeT || !e.lastMoveRotation {
+		return TSpinNone
+	}
+
+	occupied := func(c tCorner) bool {
+		return e.Field.IsOccupied(f.X+c.col, f.Y+c.row) // За границей поля угол считается занятым
+	}
+	corners := 0
+	for _, c := range []tCorner{topLeft, topRight, bottomLeft, bottomRight} {
+		if occupied(c) {
+			corners++
+		}
+	}
+	if corners < 3 {
+		return TSpinNone
+	}
+
+	front := frontCorners[f.Rotation]
+	if (occupied(front[0]) && occupied(front[1])) || (e.lastKick == fullTSpinKick && e.lastDirection != figure.Rotate180) {
+		return TSpinFull
+	}
+	return TSpinMini
+}
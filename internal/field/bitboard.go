@@ -0,0 +1,154 @@
+package field
+
+// Битовая строка ряда - uint32, где клетка x занимает бит wallBits+x. Остальные биты слева
+// и справа от поля всегда установлены и работают как стены: фигура, заехавшая за край,
+// сталкивается с ними той же операцией И, что и с занятыми клетками. Полный ряд - все биты.
+const (
+	wallBits = 4          // Ширина стены слева: фигура в матрице 4x4 не может выйти за край дальше
+	fullRow  = ^uint32(0) // Заполненный ряд вместе со стенами
+)
+
+// Mask - маска фигуры в матрице 4x4: по строке на каждую строку матрицы, бит c - колонка c
+type Mask [4]uint16
+
+// Bitboard - занятость клеток поля без их содержимого: по битовой строке на ряд сверху вниз.
+// Копия занимает несколько сотен байт, поэтому подходит для перебора положений фигур.
+type Bitboard struct {
+	width int      // Ширина поля в клетках
+	rows  []uint32 // Битовые строки рядов вместе со стенами
+	empty uint32   // Битовая строка пустого ряда: заняты только стены
+}
+
+// newBitboard создает пустую битовую доску шириной width клеток из rows рядов
+func newBitboard(width, rows int) Bitboard {
+	b := Bitboard{width: width, rows: make([]uint32, rows), empty: ^((uint32(1)<<width - 1) << wallBits)}
+	for y := range b.rows {
+		b.rows[y] = b.empty
+	}
+	return b
+}
+
+// cellBit возвращает бит клетки x в битовой строке
+func cellBit(x int) uint32 {
+	return 1 << (wallBits + x)
+}
+
+// Clone возвращает независимую копию доски
+func (b Bitboard) Clone() Bitboard {
+	b.rows = append([]uint32(nil), b.rows...)
+	return b
+}
+
+// CopyFrom копирует src в доску того же размера без выделения памяти
+func (b *Bitboard) CopyFrom(src Bitboard) {
+	b.width, b.empty = src.width, src.empty
+	b.rows = append(b.rows[:0], src.rows...)
+}
+
+// Width возвращает ширину доски в клетках
+func (b Bitboard) Width() int {
+	return b.width
+}
+
+// Rows возвращает количество рядов доски
+func (b Bitboard) Rows() int {
+	return len(b.rows)
+}
+
+// IsOccupied проверяет, занята ли клетка. За границами поля клетка всегда занята.
+func (b *Bitboard) IsOccupied(x, y int) bool {
+	if x < 0 || x >= b.width || y < 0 || y >= len(b.rows) {
+		return true
+	}
+	return b.rows[y]&cellBit(x) != 0
+}
+
+// Collides проверяет, пересекается ли фигура с маской m, левый верхний угол которой в клетке (x, y),
+// с занятыми клетками, стенами или дном поля. Над полем клетки тоже считаются занятыми.
+func (b *Bitboard) Collides(m Mask, x, y int) bool {
+	s, ok := shift(m, x)
+	return !ok || b.hits(&s, y)
+}
+
+// DropY возвращает строку, на которой остановится фигура с маской m, падая из строки y в колонке x
+func (b *Bitboard) DropY(m Mask, x, y int) int {
+	s, ok := shift(m, x)
+	if !ok {
+		return y
+	}
+	// Маска сдвигается в колонку один раз, дальше проверяются только ряды
+	for !b.hits(&s, y+1) {
+		y++
+	}
+	return y
+}
+
+// shift переводит маску фигуры в битовые строки для колонки x. Возвращает false, если непустая
+// маска в этой колонке целиком или частично за стеной дальше, чем помещается в битовую строку.
+func shift(m Mask, x int) ([4]uint32, bool) {
+	var s [4]uint32
+	if x < -wallBits || x > MaxWidth {
+		return s, m == Mask{}
+	}
+	for r, bits := range m {
+		s[r] = uint32(bits) << (x + wallBits)
+	}
+	return s, true
+}
+
+// hits проверяет, пересекаются ли сдвинутые в колонку строки фигуры s, верхняя из которых в ряду y,
+// с занятыми клетками, стенами, дном или верхом доски
+func (b *Bitboard) hits(s *[4]uint32, y int) bool {
+	for r, bits := range s {
+		if bits == 0 {
+			continue
+		}
+		row := y + r
+		if uint(row) >= uint(len(b.rows)) || bits&b.rows[row] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Place отмечает занятыми клетки фигуры с маской m в клетке (x, y). Клетки за границами поля пропускаются.
+func (b *Bitboard) Place(m Mask, x, y int) {
+	if x < -wallBits || x > b.width {
+		return
+	}
+	for r, bits := range m {
+		if row := y + r; bits != 0 && row >= 0 && row < len(b.rows) {
+			b.rows[row] |= uint32(bits) << (x + wallBits)
+		}
+	}
+}
+
+// IsRowFull проверяет, заполнен ли ряд полностью
+func (b *Bitboard) IsRowFull(y int) bool {
+	return y >= 0 && y < len(b.rows) && b.rows[y] == fullRow
+}
+
+// ClearFull удаляет все заполненные ряды одним проходом и возвращает их количество
+func (b *Bitboard) ClearFull() int {
+	dst := len(b.rows)
+	for y := len(b.rows) - 1; y >= 0; y-- {
+		if b.rows[y] != fullRow {
+			dst--
+			b.rows[dst] = b.rows[y]
+		}
+	}
+	for y := range dst {
+		b.rows[y] = b.empty
+	}
+	return dst
+}
+
+// IsEmpty проверяет, что на доске нет ни одной занятой клетки
+func (b *Bitboard) IsEmpty() bool {
+	for _, bits := range b.rows {
+		if bits != b.empty {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,423 @@
+package field
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// Тесты сравнивают операции битовой доски с прямой проверкой клеток Cells: клетка за границами
+// поля занята, внутри - занята, если в ней не CellEmpty. Бенчмарки сравнивают перебор положений
+// фигур на битовой доске с тем же перебором по клеткам:
+//
+//	go test ./internal/field -bench .
+
+// testCase - поле для проверки: ширина и высота стопки случайного мусора снизу
+type testCase struct {
+	name  string
+	width int
+	stack int // Сколько нижних рядов заполнено мусором; больше Height - мусор доходит до скрытых строк
+}
+
+var testCases = []testCase{
+	{"narrow", MinWidth, 6},
+	{"standard", DefaultWidth, 8},
+	{"wide", MaxWidth, 10},
+	{"buffer", DefaultWidth, DefaultHeight + BufferRows/2},
+}
+
+// spawnMasks - фигуры в начальном положении и размер квадрата, в котором они поворачиваются
+var spawnMasks = []struct {
+	mask Mask
+	size int
+}{
+	{Mask{0, 0b1111}, 4},      // I
+	{Mask{0b0110, 0b0110}, 4}, // O
+	{Mask{0b001, 0b111}, 3},   // L
+	{Mask{0b100, 0b111}, 3},   // J
+	{Mask{0b010, 0b111}, 3},   // T
+	{Mask{0b110, 0b011}, 3},   // Z
+	{Mask{0b011, 0b110}, 3},   // S
+}
+
+// testMasks возвращает все четыре поворота каждой фигуры
+func testMasks() []Mask {
+	var masks []Mask
+	for _, s := range spawnMasks {
+		m := s.mask
+		for range 4 {
+			masks = append(masks, m)
+			m = rotateMask(m, s.size)
+		}
+	}
+	return masks
+}
+
+// rotateMask поворачивает маску по часовой стрелке в квадрате size x size
+func rotateMask(m Mask, size int) Mask {
+	var r Mask
+	for row := range size {
+		for col := range size {
+			if m[size-1-col]&(1<<row) != 0 {
+				r[row] |= 1 << col
+			}
+		}
+	}
+	return r
+}
+
+// randomField создает поле шириной width и заполняет нижние stack рядов мусором с дыркой в каждом ряду
+func randomField(t testing.TB, width, stack int) *Field {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(uint64(width), uint64(stack)))
+	f := NewField(width, DefaultHeight, BufferRows)
+	for y := f.Rows() - stack; y < f.Rows(); y++ {
+		hole := rng.IntN(width)
+		for x := range width {
+			if x != hole && rng.IntN(10) < 7 {
+				f.Set(x, y, CellGarbage)
+			}
+		}
+	}
+	return f
+}
+
+// cellsOccupied проверяет клетку по Cells. За границами поля клетка занята.
+func cellsOccupied(f *Field, x, y int) bool {
+	return x < 0 || x >= f.Width || y < 0 || y >= f.Rows() || f.Cells[y][x] != CellEmpty
+}
+
+// cellsCollides проверяет столкновение фигуры с маской m в клетке (x, y) по Cells
+func cellsCollides(f *Field, m Mask, x, y int) bool {
+	for r, bits := range m {
+		for col := range 16 {
+			if bits&(1<<col) != 0 && cellsOccupied(f, x+col, y+r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cellsFullRows возвращает заполненные ряды по Cells
+func cellsFullRows(f *Field) []int {
+	var rows []int
+	for y, row := range f.Cells {
+		if !slices.Contains(row, CellEmpty) {
+			rows = append(rows, y)
+		}
+	}
+	return rows
+}
+
+// copyCells возвращает независимую копию клеток поля
+func copyCells(f *Field) []Row {
+	cells := make([]Row, len(f.Cells))
+	for y, row := range f.Cells {
+		cells[y] = slices.Clone(row)
+	}
+	return cells
+}
+
+// checkBits проверяет, что битовая доска поля совпадает с клетками
+func checkBits(t *testing.T, f *Field) {
+	t.Helper()
+	for y := -1; y <= f.Rows(); y++ {
+		for x := -1; x <= f.Width; x++ {
+			if got, want := f.IsOccupied(x, y), cellsOccupied(f, x, y); got != want {
+				t.Fatalf("клетка (%d, %d): занята в битах %v, в клетках %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestCollidesAndDropY(t *testing.T) {
+	masks := testMasks()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := randomField(t, tc.width, tc.stack)
+			board := f.Bitboard()
+			checkBits(t, f)
+			// Колонки и строки заходят за стены, верх и дно поля
+			for _, m := range masks {
+				for x := -wallBits - 2; x <= f.Width+2; x++ {
+					for y := -2; y <= f.Rows()+1; y++ {
+						want := cellsCollides(f, m, x, y)
+						if got := f.Collides(m, x, y); got != want {
+							t.Fatalf("маска %v в (%d, %d): столкновение %v, ожидалось %v", m, x, y, got, want)
+						}
+						if want {
+							continue
+						}
+						drop := y
+						for !cellsCollides(f, m, x, drop+1) {
+							drop++
+						}
+						if got := board.DropY(m, x, y); got != drop {
+							t.Fatalf("маска %v из (%d, %d): упала на строку %d, ожидалось %d", m, x, y, got, drop)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlace(t *testing.T) {
+	masks := testMasks()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base := randomField(t, tc.width, tc.stack)
+			// Фигуры ставятся в том числе частично за краями поля: такие клетки пропускаются
+			for _, m := range masks {
+				for x := -3; x < base.Width; x++ {
+					for _, y := range []int{-2, 0, BufferRows - 2, base.Rows() - 3} {
+						f := base.Clone()
+						want := copyCells(base)
+						for r, bits := range m {
+							for col := range 4 {
+								if bits&(1<<col) != 0 && x+col >= 0 && x+col < f.Width && y+r >= 0 && y+r < f.Rows() {
+									want[y+r][x+col] = CellT
+								}
+							}
+						}
+						f.Place(m, x, y, CellT)
+						if !slices.EqualFunc(f.Cells, want, slices.Equal) {
+							t.Fatalf("маска %v в (%d, %d): клетки поля отличаются от ожидаемых", m, x, y)
+						}
+						checkBits(t, f)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestClearRows(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := randomField(t, tc.width, tc.stack)
+			// Заполненные ряды на дне, в середине стопки и в скрытых строках
+			for _, y := range []int{f.Rows() - 1, f.Rows() - 3, f.Rows() - 4, BufferRows - 1, 2} {
+				for x := range f.Width {
+					f.Set(x, y, CellGarbage)
+				}
+			}
+			rows := cellsFullRows(f)
+			if got := f.FullRows(); !slices.Equal(got, rows) {
+				t.Fatalf("заполненные ряды %v, ожидались %v", got, rows)
+			}
+			for y := range f.Rows() {
+				if got, want := f.IsRowFull(y), slices.Contains(rows, y); got != want {
+					t.Fatalf("ряд %d: заполнен %v, ожидалось %v", y, got, want)
+				}
+			}
+
+			// Ожидаемое поле: оставшиеся ряды в прежнем порядке под пустыми
+			want := make([]Row, len(rows))
+			for y := range want {
+				want[y] = make(Row, f.Width)
+			}
+			for y, row := range copyCells(f) {
+				if !slices.Contains(rows, y) {
+					want = append(want, row)
+				}
+			}
+			board := f.Bitboard()
+			if n := board.ClearFull(); n != len(rows) {
+				t.Fatalf("ClearFull удалила %d рядов, ожидалось %d", n, len(rows))
+			}
+			f.ClearRows(rows)
+			if !slices.EqualFunc(f.Cells, want, slices.Equal) {
+				t.Fatal("клетки после очистки отличаются от ожидаемых")
+			}
+			checkBits(t, f)
+			for y := range f.Rows() {
+				for x := range f.Width {
+					if got, want := board.IsOccupied(x, y), f.IsOccupied(x, y); got != want {
+						t.Fatalf("клетка (%d, %d) после ClearFull: занята %v, ожидалось %v", x, y, got, want)
+					}
+				}
+			}
+			if len(f.FullRows()) != 0 {
+				t.Fatal("после очистки остались заполненные ряды")
+			}
+		})
+	}
+}
+
+func TestInsertRow(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := randomField(t, tc.width, tc.stack)
+			row := make(Row, f.Width)
+			for x := 1; x < f.Width; x++ {
+				row[x] = CellGarbage
+			}
+			// Ряды поднимаются, пока мусор не вытолкнет занятые клетки за верх поля
+			for range f.Rows() {
+				topEmpty := !slices.ContainsFunc(f.Cells[0], func(c Cell) bool { return c != CellEmpty })
+				want := append(copyCells(f)[1:], slices.Clone(row))
+				if ok := f.InsertRow(row); ok != topEmpty {
+					t.Fatalf("InsertRow вернула %v, верхняя строка пуста: %v", ok, topEmpty)
+				}
+				if !slices.EqualFunc(f.Cells, want, slices.Equal) {
+					t.Fatal("клетки после вставки ряда отличаются от ожидаемых")
+				}
+				checkBits(t, f)
+			}
+		})
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	f := NewField(MaxWidth, DefaultHeight, BufferRows)
+	if !f.IsEmpty() {
+		t.Fatal("новое поле не пустое")
+	}
+	// Клетки в крайних колонках и в скрытой строке
+	for _, c := range [][2]int{{0, 0}, {MaxWidth - 1, f.Rows() - 1}, {MaxWidth - 1, 0}} {
+		f.Set(c[0], c[1], CellGarbage)
+		if f.IsEmpty() {
+			t.Fatalf("поле с занятой клеткой (%d, %d) считается пустым", c[0], c[1])
+		}
+		f.Set(c[0], c[1], CellEmpty)
+		if !f.IsEmpty() {
+			t.Fatalf("поле после очистки клетки (%d, %d) не пустое", c[0], c[1])
+		}
+	}
+}
+
+// searchBoard перебирает все положения, куда фигуры падают сверху по прямой, на битовой доске.
+// Для каждого положения вызывается visit, если он задан. Возвращает число положений.
+func searchBoard(board *Bitboard, masks []Mask, visit func(m Mask, x, y int)) int {
+	n := 0
+	for _, m := range masks {
+		for x := -3; x < board.Width(); x++ {
+			y := BufferRows - 2
+			if board.Collides(m, x, y) {
+				continue
+			}
+			y = board.DropY(m, x, y)
+			n++
+			if visit != nil {
+				visit(m, x, y)
+			}
+		}
+	}
+	return n
+}
+
+// searchCells - то же, что searchBoard, с проверкой столкновений по клеткам
+func searchCells(f *Field, masks []Mask, visit func(m Mask, x, y int)) int {
+	n := 0
+	for _, m := range masks {
+		for x := -3; x < f.Width; x++ {
+			y := BufferRows - 2
+			if cellsCollides(f, m, x, y) {
+				continue
+			}
+			for !cellsCollides(f, m, x, y+1) {
+				y++
+			}
+			n++
+			if visit != nil {
+				visit(m, x, y)
+			}
+		}
+	}
+	return n
+}
+
+func TestSearchMatchesCells(t *testing.T) {
+	masks := testMasks()
+	for _, tc := range testCases {
+		f := randomField(t, tc.width, tc.stack)
+		board := f.Bitboard()
+		type placement struct {
+			m    Mask
+			x, y int
+		}
+		var bits, cells []placement
+		searchBoard(&board, masks, func(m Mask, x, y int) { bits = append(bits, placement{m, x, y}) })
+		searchCells(f, masks, func(m Mask, x, y int) { cells = append(cells, placement{m, x, y}) })
+		if !slices.Equal(bits, cells) {
+			t.Fatalf("%s: перебор по битам нашел %d положений, по клеткам %d", tc.name, len(bits), len(cells))
+		}
+	}
+}
+
+func BenchmarkCollisionBits(b *testing.B) {
+	masks := testMasks()
+	board := randomField(b, DefaultWidth, 8).Bitboard()
+	for range b.N {
+		searchBoard(&board, masks, nil)
+	}
+}
+
+func BenchmarkCollisionCells(b *testing.B) {
+	masks := testMasks()
+	f := randomField(b, DefaultWidth, 8)
+	for range b.N {
+		searchCells(f, masks, nil)
+	}
+}
+
+// clearField возвращает поле, нижние четыре ряда которого заполнены, кроме первой колонки
+func clearField(width int) *Field {
+	f := NewField(width, DefaultHeight, BufferRows)
+	for y := f.Rows() - 4; y < f.Rows(); y++ {
+		for x := 1; x < width; x++ {
+			f.Set(x, y, CellGarbage)
+		}
+	}
+	return f
+}
+
+func BenchmarkClearBits(b *testing.B) {
+	iPiece := testMasks()[1] // Вертикальная I в колонке 2 матрицы
+	board := clearField(DefaultWidth).Bitboard()
+	work := board.Clone()
+	for range b.N {
+		work.CopyFrom(board)
+		work.Place(iPiece, -2, work.Rows()-4)
+		work.ClearFull()
+	}
+}
+
+func BenchmarkClearField(b *testing.B) {
+	iPiece := testMasks()[1]
+	f := clearField(DefaultWidth)
+	for range b.N {
+		work := f.Clone()
+		work.Place(iPiece, -2, work.Rows()-4, CellI)
+		work.ClearRows(work.FullRows())
+	}
+}
+
+func BenchmarkPlacementBits(b *testing.B) {
+	masks := testMasks()
+	board := randomField(b, DefaultWidth, 8).Bitboard()
+	work := board.Clone()
+	b.ResetTimer()
+	for range b.N {
+		searchBoard(&board, masks, func(m Mask, x, y int) {
+			work.CopyFrom(board)
+			work.Place(m, x, y)
+			work.ClearFull()
+		})
+	}
+}
+
+func BenchmarkPlacementField(b *testing.B) {
+	masks := testMasks()
+	f := randomField(b, DefaultWidth, 8)
+	b.ResetTimer()
+	for range b.N {
+		searchCells(f, masks, func(m Mask, x, y int) {
+			work := f.Clone()
+			work.Place(m, x, y, CellT)
+			work.ClearRows(work.FullRows())
+		})
+	}
+}
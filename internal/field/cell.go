@@ -0,0 +1,79 @@
+package field
+
+import (
+	"fmt"
+	"strings"
+	"tetris/internal/models"
+)
+
+// Cell - содержимое клетки поля: пусто, блок одной из фигур, мусор или особый блок
+type Cell uint8
+
+const (
+	CellEmpty   Cell = iota // CellEmpty - Пустая клетка
+	CellI                   // CellI - Блок фигуры I
+	CellO                   // CellO - Блок фигуры O
+	CellL                   // CellL - Блок фигуры L
+	CellJ                   // CellJ - Блок фигуры J
+	CellT                   // CellT - Блок фигуры T
+	CellS                   // CellS - Блок фигуры S
+	CellZ                   // CellZ - Блок фигуры Z
+	CellGarbage             // CellGarbage - Блок мусорного ряда
+	CellSpecial             // CellSpecial - Особый блок, не принадлежащий ни фигуре, ни мусору
+)
+
+// cellChars - символы клеток в текстовом виде ряда, по порядку констант Cell
+const cellChars = ".IOLJTSZG*"
+
+// CellOf возвращает клетку, которую оставляет фигура shape после фиксации
+func CellOf(shape models.Shape) Cell {
+	if shape < models.ShapeI || shape > models.ShapeZ {
+		return CellSpecial
+	}
+	return CellI + Cell(shape-models.ShapeI)
+}
+
+// Shape возвращает фигуру, которой принадлежит клетка, и false для пустых клеток, мусора и особых блоков
+func (c Cell) Shape() (models.Shape, bool) {
+	if c < CellI || c > CellZ {
+		return 0, false
+	}
+	return models.ShapeI + models.Shape(c-CellI), true
+}
+
+// String возвращает символ клетки: "." - пусто, буква фигуры, "G" - мусор, "*" - особый блок
+func (c Cell) String() string {
+	if int(c) >= len(cellChars) {
+		return "?"
+	}
+	return cellChars[c : c+1]
+}
+
+// Row - ряд клеток поля. В JSON ряд записывается строкой символов клеток, например "GGGG.GGGGG".
+type Row []Cell
+
+// MarshalText записывает ряд строкой символов клеток
+func (r Row) MarshalText() ([]byte, error) {
+	text := make([]byte, len(r))
+	for x, c := range r {
+		if int(c) >= len(cellChars) {
+			return nil, fmt.Errorf("неизвестная клетка %d в ряду", c)
+		}
+		text[x] = cellChars[c]
+	}
+	return text, nil
+}
+
+// UnmarshalText читает ряд из строки символов клеток
+func (r *Row) UnmarshalText(text []byte) error {
+	row := make(Row, len(text))
+	for x, ch := range text {
+		c := strings.IndexByte(cellChars, ch)
+		if c < 0 {
+			return fmt.Errorf("неизвестный символ клетки %q в ряду", ch)
+		}
+		row[x] = Cell(c)
+	}
+	*r = row
+	return nil
+}
@@ -1,87 +1,228 @@
 package field
 
-import "log"
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
 
 const (
-	CellSize     = 32                      // CellSize - Размер одной клетки в пикселях
-	ScreenWidth  = 320                     // ScreenWidth - Ширина игрового экрана в пикселях
-	ScreenHeight = 480                     // ScreenHeight - Высота игрового экрана в пикселях
-	Rows         = ScreenHeight / CellSize // Rows - Количество строк на игровом поле
-	Cols         = ScreenWidth / CellSize  // Cols - Количество столбцов на игровом поле
+	DefaultWidth  = 10 // DefaultWidth - Ширина стандартного поля в клетках
+	DefaultHeight = 20 // DefaultHeight - Высота видимой части стандартного поля в клетках
+	BufferRows    = 20 // BufferRows - Сколько скрытых строк находится над видимой частью поля
+	MinWidth      = 4  // MinWidth - Минимальная ширина поля: в него должна помещаться любая фигура
+	MaxWidth      = 16 // MaxWidth - Максимальная ширина поля
+	MinHeight     = 4  // MinHeight - Минимальная высота видимой части поля
+	MaxHeight     = 40 // MaxHeight - Максимальная высота видимой части поля
 )
 
-// FieldCells представляет тип данных для клеток игрового поля
-type FieldCells [Rows][Cols]bool
-
-// Field представляет игровое поле
+// Field представляет игровое поле. Строки нумеруются сверху вниз: первые Buffer строк
+// скрыты над видимой частью, последняя строка - дно поля.
+//
+// Занятость клеток хранится в битовой доске (см. bitboard.go), поэтому проверка столкновений,
+// заполненности и очистка рядов - это операции над целыми строками. Cells хранит, чем занята
+// клетка, и нужна для отрисовки и сохранений.
 type Field struct {
-	Cells FieldCells // Cells - false — пусто, true — занято
+	Width  int      `json:"width"`  // Ширина поля в клетках
+	Height int      `json:"height"` // Высота видимой части поля в клетках
+	Buffer int      `json:"buffer"` // Количество скрытых строк над видимой частью
+	Cells  []Row    `json:"cells"`  // Cells[y][x] - содержимое клетки, CellEmpty — пусто
+	bits   Bitboard // Занятость клеток Cells
 }
 
-// NewField создает новое игровое поле
-func NewField() *Field {
-	f := &Field{}
-	for i := range f.Cells {
-		for j := range f.Cells[i] {
-			f.Cells[i][j] = false // Явно инициализируем все клетки как пустые
-		}
+// NewField создает новое пустое поле шириной width и высотой height видимых строк
+// с buffer скрытыми строками над ними
+func NewField(width, height, buffer int) *Field {
+	f := &Field{Width: width, Height: height, Buffer: buffer, Cells: make([]Row, height+buffer)}
+	for y := range f.Cells {
+		f.Cells[y] = make(Row, width) // Все клетки изначально пустые
 	}
-	log.Printf("создано новое поле размером %dx%d", Cols, Rows)
+	f.rebuild()
+	log.Printf("создано новое поле размером %dx%d (+%d скрытых строк)", width, height, buffer)
 	return f
 }
 
-// IsOccupied проверяет, занята ли клетка
+// UnmarshalJSON читает поле из сохранения и восстанавливает битовые строки по клеткам
+func (f *Field) UnmarshalJSON(data []byte) error {
+	type plain Field // Тип без методов, чтобы не вызвать UnmarshalJSON рекурсивно
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	// Битовая доска строится по размеру поля, поэтому размер проверяется до неё
+	if !f.validSize() {
+		return fmt.Errorf("недопустимый размер поля %dx%d (+%d скрытых строк)", f.Width, f.Height, f.Buffer)
+	}
+	f.rebuild()
+	return nil
+}
+
+// rebuild заново вычисляет битовую доску по клеткам
+func (f *Field) rebuild() {
+	f.bits = newBitboard(f.Width, len(f.Cells))
+	for y, row := range f.Cells {
+		f.bits.rows[y] = f.rowBits(row)
+	}
+}
+
+// rowBits возвращает битовую строку ряда клеток row
+func (f *Field) rowBits(row Row) uint32 {
+	bits := f.bits.empty
+	for x, c := range row {
+		if c != CellEmpty && x < f.Width {
+			bits |= cellBit(x)
+		}
+	}
+	return bits
+}
+
+// Bitboard возвращает копию занятости клеток поля для перебора положений фигур
+func (f *Field) Bitboard() Bitboard {
+	return f.bits.Clone()
+}
+
+// Rows возвращает общее количество строк вместе со скрытыми
+func (f *Field) Rows() int {
+	return f.Height + f.Buffer
+}
+
+// Clone возвращает независимую копию поля
+func (f *Field) Clone() *Field {
+	c := *f
+	c.Cells = make([]Row, len(f.Cells))
+	// Все ряды копии лежат в одном массиве
+	cells := make(Row, len(f.Cells)*f.Width)
+	for y := range f.Cells {
+		c.Cells[y] = cells[y*f.Width : (y+1)*f.Width : (y+1)*f.Width]
+		copy(c.Cells[y], f.Cells[y])
+	}
+	c.bits = f.bits.Clone()
+	return &c
+}
+
+// Valid проверяет, что размеры поля допустимы и совпадают с размером клеток
+func (f *Field) Valid() bool {
+	if !f.validSize() || len(f.Cells) != f.Rows() || f.bits.Rows() != f.Rows() {
+		return false
+	}
+	for _, row := range f.Cells {
+		if len(row) != f.Width {
+			return false
+		}
+		for _, c := range row {
+			if c > CellSpecial {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validSize проверяет, что ширина, высота и количество скрытых строк в допустимых пределах
+func (f *Field) validSize() bool {
+	return f.Width >= MinWidth && f.Width <= MaxWidth && f.Height >= MinHeight && f.Height <= MaxHeight &&
+		f.Buffer >= 0 && f.Buffer <= BufferRows
+}
+
+// IsOccupied проверяет, занята ли клетка. За границами поля клетка всегда занята.
+// Вызывается при каждой проверке столкновения, поэтому ничего не пишет в журнал.
 func (f *Field) IsOccupied(x, y int) bool {
-	// Проверка на выход за границы поля
-	if x < 0 || x >= Cols || y < 0 || y >= Rows {
-		log.Printf("попытка доступа за границы поля: x=%d, y=%d", x, y)
-		return true // Считаем, что за границей поле всегда занято
+	return f.bits.IsOccupied(x, y)
+}
+
+// Collides проверяет, пересекается ли фигура с маской m в клетке (x, y) с занятыми клетками или краями поля
+func (f *Field) Collides(m Mask, x, y int) bool {
+	return f.bits.Collides(m, x, y)
+}
+
+// At возвращает содержимое клетки. За границами поля клетки пустые.
+func (f *Field) At(x, y int) Cell {
+	if x < 0 || x >= f.Width || y < 0 || y >= f.Rows() {
+		return CellEmpty
 	}
 	return f.Cells[y][x]
 }
 
-// SetOccupied помечает клетку как занятую
-func (f *Field) SetOccupied(x, y int) {
+// Set записывает в клетку c
+func (f *Field) Set(x, y int, c Cell) {
 	// Проверка на выход за границы поля
-	if x < 0 || x >= Cols || y < 0 || y >= Rows {
-		log.Printf("попытка установить занятую клетку за границей поля: x=%d, y=%d", x, y)
+	if x < 0 || x >= f.Width || y < 0 || y >= f.Rows() {
+		log.Printf("попытка установить клетку за границей поля: x=%d, y=%d", x, y)
 		return
 	}
-	f.Cells[y][x] = true
-	log.Printf("установлена занятая клетка: x=%d, y=%d", x, y)
+	f.Cells[y][x] = c
+	if c == CellEmpty {
+		f.bits.rows[y] &^= cellBit(x)
+	} else {
+		f.bits.rows[y] |= cellBit(x)
+	}
+}
+
+// Place заполняет клетками c клетки фигуры с маской m в клетке (x, y).
+// Клетки за границами поля пропускаются.
+func (f *Field) Place(m Mask, x, y int, c Cell) {
+	for r, bits := range m {
+		for col := 0; bits != 0; col, bits = col+1, bits>>1 {
+			if bits&1 != 0 && x+col >= 0 && x+col < f.Width && y+r >= 0 && y+r < f.Rows() {
+				f.Cells[y+r][x+col] = c
+			}
+		}
+	}
+	f.bits.Place(m, x, y)
 }
 
 // IsRowFull проверяет, заполнен ли ряд полностью
 func (f *Field) IsRowFull(y int) bool {
-	// Проверка на выход за границы поля
-	if y < 0 || y >= Rows {
-		log.Printf("попытка проверить заполненность строки за границей поля: y=%d", y)
-		return false
-	}
-	for x := 0; x < Cols; x++ {
-		if !f.Cells[y][x] {
-			return false
+	return f.bits.IsRowFull(y)
+}
+
+// FullRows возвращает номера всех заполненных рядов сверху вниз
+func (f *Field) FullRows() []int {
+	var rows []int
+	for y, bits := range f.bits.rows {
+		if bits == fullRow {
+			rows = append(rows, y)
 		}
 	}
-	return true
+	return rows
 }
 
-// ClearRow удаляет заполненный ряд и сдвигает все сверху вниз
-func (f *Field) ClearRow(y int) {
-	// Проверка на выход за границы поля
-	if y < 0 || y >= Rows {
-		log.Printf("попытка удалить строку за границей поля: y=%d", y)
+// ClearRows удаляет ряды rows (номера по возрастанию) и одним проходом сдвигает оставшиеся вниз
+func (f *Field) ClearRows(rows []int) {
+	if len(rows) == 0 {
 		return
 	}
-	log.Printf("удалена заполненная строка: y=%d", y)
-	// Сдвигаем все строки сверху вниз
-	for row := y; row > 0; row-- {
-		for x := 0; x < Cols; x++ {
-			f.Cells[row][x] = f.Cells[row-1][x]
+	// Оставшиеся ряды переносятся снизу вверх, удаленные ряды переиспользуются как пустые верхние строки
+	removed := make([]Row, 0, len(rows))
+	next, dst := len(rows)-1, f.Rows()
+	for y := f.Rows() - 1; y >= 0; y-- {
+		if next >= 0 && rows[next] == y {
+			removed = append(removed, f.Cells[y])
+			next--
+			continue
 		}
+		dst--
+		f.Cells[dst], f.bits.rows[dst] = f.Cells[y], f.bits.rows[y]
 	}
-	// Очищаем верхнюю строку
-	for x := range Cols {
-		f.Cells[0][x] = false
+	for y, row := range removed {
+		clear(row)
+		f.Cells[y], f.bits.rows[y] = row, f.bits.empty
 	}
 }
+
+// IsEmpty проверяет, что на поле нет ни одной занятой клетки
+func (f *Field) IsEmpty() bool {
+	return f.bits.IsEmpty()
+}
+
+// InsertRow добавляет ряд снизу и сдвигает все поле на строку вверх.
+// Возвращает false, если занятые клетки верхней строки оказались вытолкнуты за пределы поля.
+func (f *Field) InsertRow(row Row) bool {
+	ok := f.bits.rows[0] == f.bits.empty
+	// Освободившаяся верхняя строка переиспользуется для нового ряда
+	top := f.Cells[0]
+	copy(f.Cells, f.Cells[1:])
+	copy(f.bits.rows, f.bits.rows[1:])
+	copy(top, row)
+	f.Cells[f.Rows()-1], f.bits.rows[f.Rows()-1] = top, f.rowBits(top)
+	return ok
+}
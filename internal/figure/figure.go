@@ -1,8 +1,8 @@
 package figure
 
 import (
+	"fmt"
 	"log"
-	"math/rand"
 	"tetris/internal/field"
 	"tetris/internal/models"
 )
@@ -10,23 +10,41 @@ import (
 const (
 	figureWidth  = 4
 	figureHeight = 4
+	spawnRows    = 2 // Фигура появляется в двух скрытых строках прямо над видимой частью поля
 )
 
-// NewFigure создает новую случайную фигуру
-func NewFigure(fld *field.Field) *models.Figure {
-	shape := models.Shape(rand.Intn(7)) // Выбираем случайную фигуру
+// NewFigure создает новую фигуру заданного типа в точке появления
+func NewFigure(fld *field.Field, shape models.Shape) *models.Figure {
 	fig := &models.Figure{
 		Shape: shape,
-		X:     field.Cols/2 - figureWidth/2, // Центрируем по горизонтали
-		Y:     0,                            // Фигура всегда появляется вверху
+		X:     fld.Width/2 - figureWidth/2,  // Центрируем по горизонтали
+		Y:     max(fld.Buffer-spawnRows, 0), // Фигура появляется над видимой частью поля
 	}
 	SetShape(fig, shape) // Устанавливаем форму
 	log.Printf("создана новая фигура: %s", fig.Shape)
 	return fig
 }
 
-// SetShape задает матрицу для фигуры
+// Validate проверяет фигуру из сохранения: тип, состояние поворота и то, что матрица
+// совпадает с формой фигуры в этом состоянии
+func Validate(f *models.Figure) error {
+	if !f.Shape.Valid() || !f.Rotation.Valid() {
+		return fmt.Errorf("неверная фигура %d в состоянии поворота %d", f.Shape, f.Rotation)
+	}
+	var want models.Figure
+	SetShape(&want, f.Shape)
+	if f.Shape != models.ShapeO {
+		want.Cells = rotateCells(want.Cells, boxSize(f.Shape), int(f.Rotation))
+	}
+	if f.Cells != want.Cells {
+		return fmt.Errorf("форма фигуры %s не совпадает с состоянием поворота %s", f.Shape, f.Rotation)
+	}
+	return nil
+}
+
+// SetShape задает матрицу для фигуры в начальном положении поворота
 func SetShape(f *models.Figure, shape models.Shape) {
+	f.Rotation = models.Rotation0
 	switch shape {
 	case models.ShapeI:
 		f.Cells = [4][4]bool{
@@ -82,72 +100,66 @@ func SetShape(f *models.Figure, shape models.Shape) {
 	}
 }
 
-// MoveLeft перемещает фигуру влево (если возможно)
-func MoveLeft(f *models.Figure, fld *field.Field) {
+// MoveLeft перемещает фигуру влево (если возможно) и сообщает, удалось ли это
+func MoveLeft(f *models.Figure, fld *field.Field) bool {
 	if !IsFigureCollidingAfterMove(f, fld, -1, 0) {
 		f.X--
 		log.Printf("фигура %s сдвинута влево", f.Shape)
+		return true
 	}
+	return false
 }
 
-// MoveRight перемещает фигуру вправо (если возможно)
-func MoveRight(f *models.Figure, fld *field.Field) {
+// MoveRight перемещает фигуру вправо (если возможно) и сообщает, удалось ли это
+func MoveRight(f *models.Figure, fld *field.Field) bool {
 	if !IsFigureCollidingAfterMove(f, fld, 1, 0) {
 		f.X++
 		log.Printf("фигура %s сдвинута вправо", f.Shape)
+		return true
 	}
+	return false
 }
 
-// MoveDown перемещает фигуру вниз (если возможно)
-func MoveDown(f *models.Figure, fld *field.Field) {
+// MoveDown перемещает фигуру вниз (если возможно) и сообщает, удалось ли это
+func MoveDown(f *models.Figure, fld *field.Field) bool {
 	if !IsFigureCollidingAfterMove(f, fld, 0, 1) {
 		f.Y++
 		log.Printf("фигура %s сдвинута вниз", f.Shape)
+		return true
 	}
+	return false
 }
 
-// Rotate поворачивает фигуру
-func Rotate(f *models.Figure, fld *field.Field) {
-	// Создаем временную матрицу для хранения повернутых клеток
-	var rotatedCells [4][4]bool
-
-	// Поворачиваем фигуру на 90 градусов по часовой стрелке
-	for i := range figureHeight {
-		for j := range figureWidth {
-			rotatedCells[j][figureHeight-1-i] = f.Cells[i][j]
-		}
-	}
-
-	// Создаем временную фигуру, чтобы проверить столкновения
-	tempFigure := &models.Figure{
-		Shape: f.Shape,
-		Cells: rotatedCells,
-		X:     f.X,
-		Y:     f.Y,
+// DropDistance возвращает, на сколько строк фигура может упасть до столкновения
+func DropDistance(f *models.Figure, fld *field.Field) int {
+	dist := 0
+	for !IsFigureCollidingAfterMove(f, fld, 0, dist+1) {
+		dist++
 	}
+	return dist
+}
 
-	// Проверяем, не будет ли столкновения после поворота
-	if !IsFigureCollidingAfterMove(tempFigure, fld, 0, 0) {
-		// Если столкновения нет, применяем поворот
-		f.Cells = rotatedCells
-		log.Printf("фигура %s повернута", f.Shape)
-	} else {
-		log.Printf("поворот фигуры %s невозможен: есть столкновение", f.Shape)
-	}
+// Ghost возвращает копию фигуры в той позиции, где она окажется после сброса
+func Ghost(f *models.Figure, fld *field.Field) models.Figure {
+	ghost := *f
+	ghost.Y += DropDistance(f, fld)
+	return ghost
 }
 
 // IsFigureCollidingAfterMove проверяет, будет ли столкновение после перемещения на dx, dy
 func IsFigureCollidingAfterMove(fig *models.Figure, fld *field.Field, dx, dy int) bool {
+	return fld.Collides(Mask(fig), fig.X+dx, fig.Y+dy)
+}
+
+// Mask возвращает маску клеток фигуры для проверки столкновений с битовыми строками поля
+func Mask(fig *models.Figure) field.Mask {
+	var m field.Mask
 	for row := 0; row < figureHeight; row++ {
 		for col := 0; col < figureWidth; col++ {
 			if fig.Cells[row][col] {
-				x := fig.X + col + dx
-				y := fig.Y + row + dy
-				if y >= field.Rows || x < 0 || x >= field.Cols || fld.IsOccupied(x, y) {
-					return true
-				}
+				m[row] |= 1 << col
 			}
 		}
 	}
-	return false
+	return m
 }
@@ -0,0 +1,285 @@
+package figure
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"tetris/internal/models"
+)
+
+const (
+	shapesCount     = 7 // Количество различных фигур
+	historyLength   = 4 // Длина истории генератора TGM
+	historyRerolls  = 4 // Количество попыток выбрать фигуру не из истории
+	seedStreamConst = 0x9e3779b97f4a7c15
+)
+
+// Randomizer выдает последовательность фигур
+type Randomizer interface {
+	Next() models.Shape              // Next возвращает следующую фигуру
+	State() (RandomizerState, error) // State возвращает состояние для сохранения игры
+}
+
+// RandomizerState - сохраняемое состояние генератора фигур, из которого он продолжит ту же последовательность
+type RandomizerState struct {
+	Kind     RandomizerKind `json:"kind"`               // Алгоритм генератора
+	RNG      []byte         `json:"rng,omitempty"`      // Состояние источника случайных чисел
+	Bag      []models.Shape `json:"bag,omitempty"`      // Оставшиеся в мешке фигуры
+	History  []models.Shape `json:"history,omitempty"`  // История генератора TGM
+	First    bool           `json:"first,omitempty"`    // Не выдана ли еще первая фигура (TGM)
+	Sequence []models.Shape `json:"sequence,omitempty"` // Заданная последовательность
+	Pos      int            `json:"pos,omitempty"`      // Позиция в заданной последовательности
+}
+
+// RandomizerKind задает алгоритм генератора фигур
+type RandomizerKind string
+
+const (
+	RandomizerBag7     RandomizerKind = "bag7"     // RandomizerBag7 - Мешок из 7 фигур
+	RandomizerBag14    RandomizerKind = "bag14"    // RandomizerBag14 - Мешок из 14 фигур (по две каждой)
+	RandomizerRandom   RandomizerKind = "random"   // RandomizerRandom - Независимый случайный выбор
+	RandomizerHistory  RandomizerKind = "history"  // RandomizerHistory - История из 4 фигур с повторными бросками (TGM)
+	RandomizerSequence RandomizerKind = "sequence" // RandomizerSequence - Заданная заранее последовательность
+)
+
+// NewRandomizer создает генератор фигур заданного типа.
+// Для RandomizerSequence используется sequence, для остальных - seed.
+func NewRandomizer(kind RandomizerKind, seed uint64, sequence []models.Shape) (Randomizer, error) {
+	switch kind {
+	case RandomizerBag7:
+		return &bagRandomizer{rng: newRand(seed), copies: 1}, nil
+	case RandomizerBag14:
+		return &bagRandomizer{rng: newRand(seed), copies: 2}, nil
+	case RandomizerRandom:
+		return &randomRandomizer{rng: newRand(seed)}, nil
+	case RandomizerHistory:
+		h := &historyRandomizer{rng: newRand(seed), first: true}
+		for i := range h.history {
+			h.history[i] = models.ShapeZ // TGM начинает с истории ZZZZ
+		}
+		return h, nil
+	case RandomizerSequence:
+		if len(sequence) == 0 {
+			return nil, fmt.Errorf("пустая последовательность фигур")
+		}
+		if err := validateShapes(sequence, 0); err != nil {
+			return nil, fmt.Errorf("неверная последовательность фигур: %w", err)
+		}
+		return &sequenceRandomizer{shapes: sequence}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный генератор фигур: %q", kind)
+	}
+}
+
+// RestoreRandomizer восстанавливает генератор из сохраненного состояния
+func RestoreRandomizer(st RandomizerState) (Randomizer, error) {
+	var rng *source
+	if st.Kind != RandomizerSequence {
+		rng = newRand(0)
+		if err := rng.pcg.UnmarshalBinary(st.RNG); err != nil {
+			return nil, fmt.Errorf("неверное состояние генератора %s: %w", st.Kind, err)
+		}
+	}
+	switch st.Kind {
+	case RandomizerBag7, RandomizerBag14:
+		copies := 1
+		if st.Kind == RandomizerBag14 {
+			copies = 2
+		}
+		// В мешке не может быть больше копий фигуры, чем в него кладется
+		if err := validateShapes(st.Bag, copies); err != nil {
+			return nil, fmt.Errorf("неверный мешок генератора %s: %w", st.Kind, err)
+		}
+		return &bagRandomizer{rng: rng, copies: copies, bag: st.Bag}, nil
+	case RandomizerRandom:
+		return &randomRandomizer{rng: rng}, nil
+	case RandomizerHistory:
+		h := &historyRandomizer{rng: rng, first: st.First}
+		if len(st.History) != historyLength {
+			return nil, fmt.Errorf("неверная длина истории генератора: %d", len(st.History))
+		}
+		if err := validateShapes(st.History, 0); err != nil {
+			return nil, fmt.Errorf("неверная история генератора: %w", err)
+		}
+		copy(h.history[:], st.History)
+		return h, nil
+	case RandomizerSequence:
+		if len(st.Sequence) == 0 || st.Pos < 0 || st.Pos >= len(st.Sequence) {
+			return nil, fmt.Errorf("неверное состояние последовательности фигур")
+		}
+		if err := validateShapes(st.Sequence, 0); err != nil {
+			return nil, fmt.Errorf("неверная последовательность фигур: %w", err)
+		}
+		return &sequenceRandomizer{shapes: st.Sequence, pos: st.Pos}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный генератор фигур: %q", st.Kind)
+	}
+}
+
+// validateShapes проверяет, что все фигуры известны и каждая встречается не больше maxCopies раз
+// (0 - без ограничения)
+func validateShapes(shapes []models.Shape, maxCopies int) error {
+	var counts [shapesCount]int
+	for _, s := range shapes {
+		if !s.Valid() {
+			return fmt.Errorf("неизвестная фигура %d", s)
+		}
+		counts[s]++
+		if maxCopies > 0 && counts[s] > maxCopies {
+			return fmt.Errorf("фигура %s встречается больше %d раз", s, maxCopies)
+		}
+	}
+	return nil
+}
+
+// ParseShapes разбирает последовательность фигур из строки вида "IOTSZJL"
+func ParseShapes(s string) ([]models.Shape, error) {
+	shapes := make([]models.Shape, 0, len(s))
+	for _, r := range strings.ToUpper(s) {
+		shape, ok := shapeByLetter[r]
+		if !ok {
+			return nil, fmt.Errorf("неизвестная фигура %q в последовательности %q", r, s)
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes, nil
+}
+
+// shapeByLetter сопоставляет букву фигуры с её типом
+var shapeByLetter = map[rune]models.Shape{
+	'I': models.ShapeI,
+	'O': models.ShapeO,
+	'L': models.ShapeL,
+	'J': models.ShapeJ,
+	'T': models.ShapeT,
+	'S': models.ShapeS,
+	'Z': models.ShapeZ,
+}
+
+// source - источник случайных чисел, состояние которого можно сохранить
+type source struct {
+	pcg *rand.PCG
+	*rand.Rand
+}
+
+// newRand создает источник случайных чисел с явно заданным зерном
+func newRand(seed uint64) *source {
+	pcg := rand.NewPCG(seed, seed^seedStreamConst)
+	return &source{pcg: pcg, Rand: rand.New(pcg)}
+}
+
+// state возвращает состояние источника в двоичном виде
+func (s *source) state() ([]byte, error) {
+	return s.pcg.MarshalBinary()
+}
+
+// bagRandomizer выдает фигуры из перемешанного мешка, пока он не опустеет
+type bagRandomizer struct {
+	rng    *source
+	copies int            // Сколько копий каждой фигуры кладется в мешок
+	bag    []models.Shape // Оставшиеся в мешке фигуры
+}
+
+// Next возвращает следующую фигуру из мешка
+func (b *bagRandomizer) Next() models.Shape {
+	if len(b.bag) == 0 {
+		for range b.copies {
+			for s := range shapesCount {
+				b.bag = append(b.bag, models.Shape(s))
+			}
+		}
+		b.rng.Shuffle(len(b.bag), func(i, j int) {
+			b.bag[i], b.bag[j] = b.bag[j], b.bag[i]
+		})
+	}
+	shape := b.bag[0]
+	b.bag = b.bag[1:]
+	return shape
+}
+
+// State возвращает состояние мешка
+func (b *bagRandomizer) State() (RandomizerState, error) {
+	rng, err := b.rng.state()
+	kind := RandomizerBag7
+	if b.copies == 2 {
+		kind = RandomizerBag14
+	}
+	return RandomizerState{Kind: kind, RNG: rng, Bag: append([]models.Shape(nil), b.bag...)}, err
+}
+
+// randomRandomizer выбирает каждую фигуру независимо
+type randomRandomizer struct {
+	rng *source
+}
+
+// Next возвращает случайную фигуру
+func (r *randomRandomizer) Next() models.Shape {
+	return models.Shape(r.rng.IntN(shapesCount))
+}
+
+// State возвращает состояние генератора
+func (r *randomRandomizer) State() (RandomizerState, error) {
+	rng, err := r.rng.state()
+	return RandomizerState{Kind: RandomizerRandom, RNG: rng}, err
+}
+
+// historyRandomizer повторно бросает кость, если фигура есть среди последних четырех
+type historyRandomizer struct {
+	rng     *source
+	history [historyLength]models.Shape // Последние выданные фигуры
+	first   bool                        // Первая фигура игры не бывает S, Z или O
+}
+
+// Next возвращает следующую фигуру с учетом истории
+func (h *historyRandomizer) Next() models.Shape {
+	var shape models.Shape
+	if h.first {
+		h.first = false
+		starts := []models.Shape{models.ShapeI, models.ShapeL, models.ShapeJ, models.ShapeT}
+		shape = starts[h.rng.IntN(len(starts))]
+	} else {
+		for range historyRerolls {
+			shape = models.Shape(h.rng.IntN(shapesCount))
+			if !h.inHistory(shape) {
+				break
+			}
+		}
+	}
+	copy(h.history[1:], h.history[:historyLength-1])
+	h.history[0] = shape
+	return shape
+}
+
+// inHistory проверяет, выдавалась ли фигура недавно
+func (h *historyRandomizer) inHistory(shape models.Shape) bool {
+	for _, s := range h.history {
+		if s == shape {
+			return true
+		}
+	}
+	return false
+}
+
+// State возвращает состояние генератора вместе с историей
+func (h *historyRandomizer) State() (RandomizerState, error) {
+	rng, err := h.rng.state()
+	return RandomizerState{Kind: RandomizerHistory, RNG: rng, History: append([]models.Shape(nil), h.history[:]...), First: h.first}, err
+}
+
+// sequenceRandomizer циклически выдает заданную последовательность
+type sequenceRandomizer struct {
+	shapes []models.Shape
+	pos    int // Индекс следующей фигуры
+}
+
+// Next возвращает следующую фигуру последовательности
+func (s *sequenceRandomizer) Next() models.Shape {
+	shape := s.shapes[s.pos]
+	s.pos = (s.pos + 1) % len(s.shapes)
+	return shape
+}
+
+// State возвращает позицию в последовательности
+func (s *sequenceRandomizer) State() (RandomizerState, error) {
+	return RandomizerState{Kind: RandomizerSequence, Sequence: s.shapes, Pos: s.pos}, nil
+}
@@ -0,0 +1,145 @@
+package figure
+
+import (
+	"log"
+	"tetris/internal/field"
+	"tetris/internal/models"
+)
+
+// RotationDirection задает направление поворота в четвертях оборота по часовой стрелке
+type RotationDirection int
+
+const (
+	RotateCW  RotationDirection = 1 // RotateCW - Поворот по часовой стрелке
+	Rotate180 RotationDirection = 2 // Rotate180 - Поворот на 180°
+	RotateCCW RotationDirection = 3 // RotateCCW - Поворот против часовой стрелки
+)
+
+// MaxKicks - наибольшее количество тестов смещения в одном переходе (у поворотов на 180°)
+const MaxKicks = 6
+
+// kick - смещение фигуры при попытке поворота (ось Y направлена вверх, как в таблицах SRS)
+type kick struct {
+	dx, dy int
+}
+
+// kickTable хранит тесты смещения для каждого перехода [из состояния][в состояние]
+type kickTable [4][4][]kick
+
+// jlstzKicks - таблица смещений SRS для фигур J, L, S, T, Z
+var jlstzKicks = kickTable{
+	models.Rotation0: {
+		models.RotationR: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+		models.Rotation2: {{0, 0}, {0, 1}, {1, 1}, {-1, 1}, {1, 0}, {-1, 0}},
+		models.RotationL: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	},
+	models.RotationR: {
+		models.Rotation0: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+		models.Rotation2: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+		models.RotationL: {{0, 0}, {1, 0}, {1, 2}, {1, 1}, {0, 2}, {0, 1}},
+	},
+	models.Rotation2: {
+		models.Rotation0: {{0, 0}, {0, -1}, {-1, -1}, {1, -1}, {-1, 0}, {1, 0}},
+		models.RotationR: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+		models.RotationL: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	},
+	models.RotationL: {
+		models.Rotation0: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+		models.RotationR: {{0, 0}, {-1, 0}, {-1, 2}, {-1, 1}, {0, 2}, {0, 1}},
+		models.Rotation2: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	},
+}
+
+// iKicks - таблица смещений SRS для фигуры I
+var iKicks = kickTable{
+	models.Rotation0: {
+		models.RotationR: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+		models.Rotation2: {{0, 0}, {0, 1}, {1, 1}, {-1, 1}, {1, 0}, {-1, 0}},
+		models.RotationL: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+	},
+	models.RotationR: {
+		models.Rotation0: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+		models.Rotation2: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+		models.RotationL: {{0, 0}, {1, 0}, {1, 2}, {1, 1}, {0, 2}, {0, 1}},
+	},
+	models.Rotation2: {
+		models.Rotation0: {{0, 0}, {0, -1}, {-1, -1}, {1, -1}, {-1, 0}, {1, 0}},
+		models.RotationR: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+		models.RotationL: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	},
+	models.RotationL: {
+		models.Rotation0: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+		models.RotationR: {{0, 0}, {-1, 0}, {-1, 2}, {-1, 1}, {0, 2}, {0, 1}},
+		models.Rotation2: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	},
+}
+
+// boxSize возвращает размер квадрата, внутри которого вращается фигура
+func boxSize(shape models.Shape) int {
+	if shape == models.ShapeI {
+		return 4
+	}
+	return 3
+}
+
+// rotateCells поворачивает матрицу внутри квадрата size x size на times четвертей по часовой стрелке
+func rotateCells(cells [4][4]bool, size, times int) [4][4]bool {
+	for ; times > 0; times-- {
+		var rotated [4][4]bool
+		for row := range size {
+			for col := range size {
+				rotated[col][size-1-row] = cells[row][col]
+			}
+		}
+		cells = rotated
+	}
+	return cells
+}
+
+// Rotate поворачивает фигуру по SRS, перебирая тесты смещения от стен и других фигур.
+// Возвращает номер сработавшего теста и признак успешного поворота.
+func Rotate(f *models.Figure, fld *field.Field, dir RotationDirection) (int, bool) {
+	from := f.Rotation
+	rotated, kick, ok := Rotated(f, fld, dir)
+	if !ok {
+		log.Printf("поворот фигуры %s %s->%s невозможен: есть столкновение", f.Shape, from, rotated.Rotation)
+		return 0, false
+	}
+	*f = rotated
+	log.Printf("фигура %s повернута %s->%s (тест %d)", f.Shape, from, f.Rotation, kick)
+	return kick, true
+}
+
+// Rotated возвращает фигуру после поворота по SRS, не меняя исходную.
+// Нужна для перебора положений, где каждое движение не должно попадать в журнал.
+func Rotated(f *models.Figure, fld *field.Field, dir RotationDirection) (models.Figure, int, bool) {
+	from := f.Rotation
+	to := (from + models.Rotation(dir)) % 4
+
+	// Фигура O не меняет формы и не смещается при повороте
+	if f.Shape == models.ShapeO {
+		rotated := *f
+		rotated.Rotation = to
+		return rotated, 0, true
+	}
+
+	// Создаем временную фигуру, чтобы проверить столкновения
+	tempFigure := models.Figure{
+		Shape:    f.Shape,
+		Cells:    rotateCells(f.Cells, boxSize(f.Shape), int(dir)),
+		Rotation: to,
+	}
+
+	kicks := jlstzKicks[from][to]
+	if f.Shape == models.ShapeI {
+		kicks = iKicks[from][to]
+	}
+	for i, k := range kicks {
+		tempFigure.X = f.X + k.dx
+		tempFigure.Y = f.Y - k.dy // В таблицах SRS ось Y направлена вверх
+		if !IsFigureCollidingAfterMove(&tempFigure, fld, 0, 0) {
+			return tempFigure, i, true
+		}
+	}
+	return tempFigure, 0, false
+}
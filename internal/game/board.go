@@ -0,0 +1,138 @@
+package game
+
+import (
+	"image/color"
+	"tetris/internal/engine"
+	"tetris/internal/field"
+	"tetris/internal/figure"
+	"tetris/internal/models"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	boardWidth  = 320 // Ширина области поля в пикселях
+	boardHeight = 480 // Высота области поля в пикселях
+)
+
+// cellColors - цвета клеток поля: фигуры окрашены по гайдлайну, мусор серый
+var cellColors = map[field.Cell]color.RGBA{
+	field.CellEmpty:   emptyCellColor,
+	field.CellI:       {0, 240, 240, 255}, // Голубой
+	field.CellO:       {240, 240, 0, 255}, // Желтый
+	field.CellL:       {240, 160, 0, 255}, // Оранжевый
+	field.CellJ:       {0, 0, 240, 255},   // Синий
+	field.CellT:       {160, 0, 240, 255}, // Фиолетовый
+	field.CellS:       {0, 240, 0, 255},   // Зеленый
+	field.CellZ:       {240, 0, 0, 255},   // Красный
+	field.CellGarbage: {110, 110, 110, 255},
+	field.CellSpecial: {255, 255, 255, 255},
+}
+
+// cellColor возвращает цвет клетки поля
+func cellColor(c field.Cell) color.RGBA {
+	return cellColors[c]
+}
+
+// shapeColor возвращает цвет фигуры shape
+func shapeColor(shape models.Shape) color.RGBA {
+	return cellColors[field.CellOf(shape)]
+}
+
+// board переводит координаты клеток поля в пиксели. Размер клетки подбирается так,
+// чтобы видимая часть поля любого размера целиком поместилась в область поля.
+type board struct {
+	cell   int // Размер клетки в пикселях
+	x0, y0 int // Левый верхний угол видимой части поля
+	buffer int // Скрытые строки над видимой частью
+}
+
+// newBoard подбирает размер клетки и положение поля fld внутри области поля
+func newBoard(fld *field.Field) board {
+	cell := min(boardWidth/fld.Width, boardHeight/fld.Height)
+	return board{
+		cell:   cell,
+		x0:     (boardWidth - cell*fld.Width) / 2,
+		y0:     (boardHeight - cell*fld.Height) / 2,
+		buffer: fld.Buffer,
+	}
+}
+
+// position возвращает пиксельные координаты левого верхнего угла клетки (x, y)
+func (b board) position(x, y int) (float64, float64) {
+	return float64(b.x0 + x*b.cell), float64(b.y0 + (y-b.buffer)*b.cell)
+}
+
+// drawCell отрисовывает клетку (x, y) цвета c. Клетки в скрытых строках не рисуются.
+func (b board) drawCell(screen *ebiten.Image, x, y int, c color.Color) {
+	if y < b.buffer {
+		return
+	}
+	cell := ebiten.NewImage(b.cell-2, b.cell-2)
+	cell.Fill(c)
+	op := &ebiten.DrawImageOptions{}
+	px, py := b.position(x, y)
+	op.GeoM.Translate(px+1, py+1)
+	screen.DrawImage(cell, op)
+}
+
+// drawGhostCell отрисовывает контур клетки фигуры-призрака цвета c
+func (b board) drawGhostCell(screen *ebiten.Image, x, y int, c color.Color) {
+	if y < b.buffer {
+		return
+	}
+	b.drawCell(screen, x, y, c)
+
+	inner := ebiten.NewImage(b.cell-2-2*ghostOutlineWidth, b.cell-2-2*ghostOutlineWidth)
+	inner.Fill(emptyCellColor)
+	op := &ebiten.DrawImageOptions{}
+	px, py := b.position(x, y)
+	op.GeoM.Translate(px+1+ghostOutlineWidth, py+1+ghostOutlineWidth)
+	screen.DrawImage(inner, op)
+}
+
+// drawFigure отрисовывает падающую фигуру и её контур в месте приземления
+func drawFigure(screen *ebiten.Image, b board, e *engine.Engine) {
+	// Фигура на опоре темнеет по мере истечения задержки фиксации
+	figureColor := shapeColor(e.Figure.Shape)
+	pieceColor := lerpColor(figureColor, lockedFigureColor, e.LockProgress()*lockedFigureShade)
+	// Сначала контур фигуры в месте приземления
+	ghost := figure.Ghost(e.Figure, e.Field)
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			if ghost.Cells[row][col] {
+				b.drawGhostCell(screen, ghost.X+col, ghost.Y+row, figureColor)
+			}
+		}
+	}
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			if e.Figure.Cells[row][col] {
+				b.drawCell(screen, e.Figure.X+col, e.Figure.Y+row, pieceColor)
+			}
+		}
+	}
+}
+
+// drawClearingRows отрисовывает исчезновение очищаемых рядов: ряды вспыхивают белым
+// и растворяются от середины к краям
+func (b board) drawClearingRows(screen *ebiten.Image, e *engine.Engine) {
+	if e.Phase != engine.PhaseLineClear {
+		return
+	}
+	progress := e.PhaseProgress()
+	for _, y := range e.ClearingRows {
+		for x := 0; x < e.Field.Width; x++ {
+			c := lerpColor(cellColor(e.Field.At(x, y)), clearFlashColor, 1-progress)
+			// Расстояние от середины ряда в половинах клетки
+			center := 2*x + 1 - e.Field.Width
+			if center < 0 {
+				center = -center
+			}
+			if float64(center) < progress*float64(e.Field.Width+1) {
+				c = emptyCellColor
+			}
+			b.drawCell(screen, x, y, c)
+		}
+	}
+}
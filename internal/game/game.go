@@ -3,280 +3,305 @@ package game
 import (
 	"fmt"
 	"image/color"
-	"tetris/internal/field"
+	"tetris/internal/engine"
 	"tetris/internal/figure"
+	"tetris/internal/input"
 	"tetris/internal/models"
+	"tetris/internal/replay"
+	"tetris/internal/ui"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
 )
 
 const (
-	emptyCellColorValue    = 200
-	occupiedCellColorValue = 0
-	figureColorValue       = 255
-	oneLineScore           = 100
-	twoLineScore           = 300
-	threeLineScore         = 700
-	fourLineScore          = 1500
+	emptyCellColorValue = 200
+	ghostOutlineWidth   = 2    // Толщина контура фигуры-призрака
+	lockedFigureShade   = 0.65 // Насколько темнеет фигура к концу задержки фиксации
+	//Callout
+	calloutDuration   = time.Second * 2 // Сколько держится подпись об очистке
+	calloutRectWidth  = 200
+	calloutLineHeight = 18 // Высота одной строки подписи
+	calloutPadding    = 6
+	calloutRectX      = (boardWidth - calloutRectWidth) / 2
+	calloutRectY      = 40
+	maxPlayers        = 4                                 // Максимальное количество локальных игроков
+	playerWidth       = boardWidth + scoreBoardWidth + 10 // Ширина поля и панели одного игрока
 	//Score board
-	scoreBoardWidth  = 150
-	scoreBoardHeight = 50
+	scoreBoardWidth      = 150
+	scoreBoardHeight     = 106
+	scoreBoardLineHeight = 18 // Высота строки табло
 	//Game over
 	gameOverRectWidth  = 200
 	gameOverRectHeight = 100
-	gameOverRectX      = (field.ScreenWidth - gameOverRectWidth) / 2
-	gameOverRectY      = (field.ScreenHeight - gameOverRectHeight) / 2
+	gameOverRectX      = (boardWidth - gameOverRectWidth) / 2
+	gameOverRectY      = (boardHeight - gameOverRectHeight) / 2
 	//Расположение табло
-	scoreBoardX = field.ScreenWidth + 10
+	scoreBoardX = boardWidth + 10
 	scoreBoardY = 10
 	//Pause Rect
 	pauseRectWidth  = scoreBoardWidth
 	pauseRectHeight = 30
 	pauseRectX      = scoreBoardX
 	pauseRectY      = scoreBoardY + scoreBoardHeight + 10
+	//Next queue
+	nextRectX        = scoreBoardX
+	nextRectY        = pauseRectY + pauseRectHeight + 10
+	nextRectWidth    = scoreBoardWidth
+	nextHeaderHeight = 20                 // Высота заголовка "Next"
+	miniCellSize     = 12                 // Размер клетки мини-фигуры
+	nextSlotHeight   = miniCellSize*2 + 8 // Высота места под одну фигуру очереди
+	//Hold
+	holdRectX      = scoreBoardX
+	holdRectY      = nextRectY + nextHeaderHeight + engine.MaxNextCount*nextSlotHeight + 10
+	holdRectWidth  = scoreBoardWidth
+	holdRectHeight = nextHeaderHeight + nextSlotHeight
 )
 
 // Переменные для цветов
 var (
-	emptyCellColor    = color.RGBA{emptyCellColorValue, emptyCellColorValue, emptyCellColorValue, 255}    // Серый (пустая клетка)
-	occupiedCellColor = color.RGBA{occupiedCellColorValue, occupiedCellColorValue, figureColorValue, 255} // Синий (занятая клетка)
-	figureColor       = color.RGBA{figureColorValue, occupiedCellColorValue, occupiedCellColorValue, 255} // Красный цвет
-	textColor         = color.RGBA{0, 0, 0, 255}                                                          // Черный цвет
-	scoreBoardColor   = color.RGBA{200, 200, 200, 255}                                                    // Серый цвет для рамки поля со счетом
+	emptyCellColor    = color.RGBA{emptyCellColorValue, emptyCellColorValue, emptyCellColorValue, 255} // Серый (пустая клетка)
+	lockedFigureColor = color.RGBA{0, 0, 0, 255}                                                       // Цвет, к которому темнеет фигура перед фиксацией
+	clearFlashColor   = color.RGBA{255, 255, 255, 255}                                                 // Белая вспышка очищаемых рядов
+	textColor         = color.RGBA{0, 0, 0, 255}                                                       // Черный цвет
+	scoreBoardColor   = color.RGBA{200, 200, 200, 255}                                                 // Серый цвет для рамки поля со счетом
 	gameOverRectColor = color.RGBA{100, 100, 100, 255}
 	pauseRectColor    = color.RGBA{200, 200, 200, 255}
+	nextRectColor     = color.RGBA{200, 200, 200, 255}
+	holdRectColor     = color.RGBA{200, 200, 200, 255}
+	calloutRectColor  = color.RGBA{255, 220, 120, 255}
+	holdUsedColor     = color.RGBA{120, 120, 120, 255} // Серый цвет отложенной фигуры, когда обмен уже использован
 )
 
-// Game управляет игрой
-type Game struct {
-	Field        *field.Field
-	Figure       *models.Figure
-	LastDrop     time.Time
-	DropInterval time.Duration
-	GameOver     bool
-	//Переменные для сдвига
-	LastHorizontalMove     time.Time     // Время последнего горизонтального сдвига
-	HorizontalMoveInterval time.Duration // Интервал между горизонтальными сдвигами
-	HorizontalMoveDelay    time.Duration // Задержка перед началом повторных сдвигов
-	MovingHorizontally     bool          // Движется ли фигура влево/вправо
-	HorizontalDirection    int           // Направление сдвига (0 - нет, -1 - влево, 1 - вправо)
-	//Переменные для поворота
-	LastRotate     time.Time     // Время последнего поворота
-	RotateInterval time.Duration // Интервал между поворотами
-	//Счет
-	Score    int       // Текущий счет
-	fontFace font.Face // Шрифт
-	//Пауза
-	Paused        bool          //На паузе ли игра?
-	LastPause     time.Time     // Время последнего переключения паузы
-	PauseInterval time.Duration // Интервал между переключениями
+// Options задает параметры окна игры, не относящиеся к правилам
+type Options struct {
+	Players         int                   // Количество локальных игроков, у каждого свое поле
+	Handling        []engine.Handling     // Настройки управления каждого игрока (у недостающих - из настроек игры)
+	Keyboard        *input.Keyboard       // Клавиатура с раскладкой первого игрока
+	GamepadProfiles input.GamepadProfiles // Раскладки геймпадов
+	RecordPath      string                // Файл, в который записывается повтор (пусто - не записывать)
+	Replay          *replay.Replay        // Повтор для просмотра вместо игры (nil - обычная игра)
+	SaveDir         string                // Каталог сохранений (пусто - сохранения недоступны)
+	Autosave        bool                  // Сохранять незаконченную игру при выходе
+	Resume          bool                  // Продолжить игру, сохраненную при прошлом выходе
+	PersonalBest    []time.Duration       // Промежуточные времена личного рекорда для сравнения по ходу игры
 }
 
-// NewGame создает новую игру
-func NewGame() *Game {
-	g := &Game{
-		Field:                  field.NewField(),
-		LastDrop:               time.Now(),
-		DropInterval:           time.Second / 2, // Фигура падает раз в 0.5 секунды
-		GameOver:               false,
-		HorizontalMoveInterval: time.Millisecond * 50,  // Интервал между повторными сдвигами
-		HorizontalMoveDelay:    time.Millisecond * 250, // Задержка перед повторными сдвигами
-		MovingHorizontally:     false,
-		HorizontalDirection:    0,
-		LastRotate:             time.Now(),
-		RotateInterval:         time.Millisecond * 200, // Интервал между поворотами
-		Score:                  0,                      // Изначальный счет - 0
-		fontFace:               basicfont.Face7x13,
-		Paused:                 false,
-		LastPause:              time.Now(),
-		PauseInterval:          time.Millisecond * 200, //Интервал между паузами
-	}
-	g.Figure = figure.NewFigure(g.Field)
-	return g
+// Player - локальный игрок со своим полем
+type Player struct {
+	Engine  *engine.Engine // Состояние и правила игры игрока
+	canvas  *ebiten.Image  // Изображение, на котором рисуются поле и панель игрока
+	ignored uint16         // Клавиши, нажатые до начала или возобновления игры: не действуют, пока их не отпустят
 }
 
-// Update обновляет игру (каждый кадр)
-func (g *Game) Update() error {
-	// Проверяем, нажата ли клавиша "P" и не прошло ли еще достаточно времени с момента последнего переключения паузы
-	if ebiten.IsKeyPressed(ebiten.KeyP) && time.Since(g.LastPause) > g.PauseInterval {
-		g.Paused = !g.Paused
-		g.LastPause = time.Now()
-	}
+// Game связывает движки игроков с Ebiten: читает клавиатуру и геймпады и отрисовывает состояние
+type Game struct {
+	Players    []*Player       // Локальные игроки
+	keyboard   *input.Keyboard // Клавиатура с раскладкой первого игрока
+	inputs     *input.Manager  // Распределение устройств ввода между игроками
+	recording  *replay.Replay  // Записываемый повтор (nil, если запись не ведется)
+	recordPath string          // Файл для записи повтора
+	playback   *playback       // Просмотр повтора (nil в обычной игре)
+	saveDir    string          // Каталог сохранений
+	autosave   bool            // Сохранять игру при выходе
+	menu       *ui.Menu        // Открытое меню игры (nil, если закрыто)
+	quit       bool            // Игрок выбрал выход из игры в главное меню
+	controls   bool            // Игрок выбрал в меню игры настройку клавиш
+	finished   bool            // Все игроки закончили игру и подтвердили переход к итогам
+	best       []time.Duration // Промежуточные времена личного рекорда
+	fontFace   font.Face       // Шрифт
+}
 
-	if g.GameOver && ebiten.IsKeyPressed(ebiten.KeyR) {
-		g.RestartGame()
-		return nil
-	}
-	if g.GameOver || g.Paused {
-		return nil
+// NewGame создает новую игру с заданными настройками
+func NewGame(cfg engine.Config, opts Options) (*Game, error) {
+	g := &Game{
+		keyboard:   opts.Keyboard,
+		recordPath: opts.RecordPath,
+		saveDir:    opts.SaveDir,
+		autosave:   opts.Autosave && opts.SaveDir != "",
+		best:       opts.PersonalBest,
+		fontFace:   basicfont.Face7x13,
 	}
-
-	// Обработка горизонтальных перемещений
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-		g.moveHorizontally(-1)
-	} else if ebiten.IsKeyPressed(ebiten.KeyRight) {
-		g.moveHorizontally(1)
-	} else {
-		g.MovingHorizontally = false
-		g.HorizontalDirection = 0
+	if g.keyboard == nil {
+		g.keyboard = &input.Keyboard{Bindings: input.DefaultBindings()}
 	}
-
-	//Если есть направление, но кнопки не нажаты, значит, надо продолжать двигать
-	if g.HorizontalDirection != 0 {
-		if time.Since(g.LastHorizontalMove) > g.HorizontalMoveInterval {
-			g.moveHorizontally(g.HorizontalDirection)
-			g.LastHorizontalMove = time.Now()
+	if opts.Replay != nil {
+		player, err := replay.NewPlayer(opts.Replay)
+		if err != nil {
+			return nil, err
+		}
+		g.playback = &playback{player: player}
+		for _, e := range player.Engines {
+			g.Players = append(g.Players, &Player{Engine: e, canvas: ebiten.NewImage(playerWidth, boardHeight)})
 		}
+		return g, nil
 	}
 
-	// Поворот
-	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		if time.Since(g.LastRotate) > g.RotateInterval {
-			figure.Rotate(g.Figure, g.Field)
-			g.LastRotate = time.Now()
+	if opts.Players < 1 || opts.Players > maxPlayers {
+		return nil, fmt.Errorf("количество игроков %d вне диапазона 1-%d", opts.Players, maxPlayers)
+	}
+	handling := make([]engine.Handling, opts.Players)
+	for i := range opts.Players {
+		// У всех игроков одинаковое зерно, чтобы последовательность фигур была честной,
+		// а чувствительность управления у каждого своя
+		pcfg := cfg
+		if i < len(opts.Handling) {
+			pcfg.Handling = opts.Handling[i]
 		}
+		handling[i] = pcfg.Handling
+		e, err := engine.NewEngine(pcfg)
+		if err != nil {
+			return nil, err
+		}
+		// Клавиши, которыми игру запустили из меню, не должны сразу сбросить фигуру
+		g.Players = append(g.Players, &Player{Engine: e, canvas: ebiten.NewImage(playerWidth, boardHeight), ignored: ^uint16(0)})
 	}
 
-	// Ускорение падения вниз при нажатии
-	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		figure.MoveDown(g.Figure, g.Field)
+	g.inputs = input.NewManager(opts.Players, g.keyboard, opts.GamepadProfiles)
+	if opts.RecordPath != "" {
+		g.recording = replay.New(cfg, ebiten.TPS(), opts.Players)
+		g.recording.Handling = handling
+	}
+	if opts.Resume && g.saveDir != "" {
+		if err := g.resumeAutosave(); err != nil {
+			return nil, err
+		}
 	}
+	return g, nil
+}
 
-	// Автоматическое падение фигуры по таймеру
-	if time.Since(g.LastDrop) > g.DropInterval {
-		if !g.IsFigureCollidingAfterMove() {
-			figure.MoveDown(g.Figure, g.Field) // Фигура двигается вниз
-		} else {
-			// Фигура столкнулась с дном или другой фигурой -> фиксируем её
-			g.FixFigure()
-			g.ClearFullRows()
+// ScreenSize возвращает размер экрана игры для заданного количества игроков
+func ScreenSize(players int) (int, int) {
+	return players * playerWidth, boardHeight
+}
 
-			// Создаем новую фигуру
-			g.Figure = figure.NewFigure(g.Field)
+// Finished сообщает, что все игроки закончили игру и подтвердили переход к итогам
+func (g *Game) Finished() bool {
+	return g.finished
+}
 
-			// Если новая фигура сразу сталкивается, значит, конец игры
-			if g.IsFigureColliding() {
-				g.GameOver = true
-			}
+// over сообщает, что все игроки закончили игру
+func (g *Game) over() bool {
+	if g.playback != nil {
+		return false
+	}
+	for _, p := range g.Players {
+		if !p.Engine.GameOver {
+			return false
 		}
-		g.LastDrop = time.Now()
 	}
+	return true
+}
 
-	return nil
+// QuitRequested сообщает, что игрок выбрал выход в главное меню
+func (g *Game) QuitRequested() bool {
+	return g.quit
 }
 
-// moveHorizontally перемещает фигуру по горизонтали в заданном направлении
-func (g *Game) moveHorizontally(direction int) {
-	if !g.MovingHorizontally {
-		if direction == -1 {
-			figure.MoveLeft(g.Figure, g.Field)
-		} else if direction == 1 {
-			figure.MoveRight(g.Figure, g.Field)
-		}
-		g.LastHorizontalMove = time.Now()
-		g.MovingHorizontally = true
-		g.HorizontalDirection = direction
-	} else if time.Since(g.LastHorizontalMove) > g.HorizontalMoveDelay {
-		if direction == -1 {
-			figure.MoveLeft(g.Figure, g.Field)
-		} else if direction == 1 {
-			figure.MoveRight(g.Figure, g.Field)
-		}
-		g.LastHorizontalMove = time.Now()
-	}
+// ControlsRequested сообщает, что игрок выбрал в меню игры настройку клавиш; запрос сбрасывается
+func (g *Game) ControlsRequested() bool {
+	requested := g.controls
+	g.controls = false
+	return requested
 }
 
-// FixFigure фиксирует фигуру в поле
-func (g *Game) FixFigure() {
-	for row := 0; row < 4; row++ {
-		for col := 0; col < 4; col++ {
-			if g.Figure.Cells[row][col] {
-				x := g.Figure.X + col
-				y := g.Figure.Y + row
-				g.Field.SetOccupied(x, y) // Фиксируем все клетки фигуры
-			}
+// Update обновляет игру (каждый кадр)
+func (g *Game) Update() error {
+	if g.playback != nil {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			g.quit = true
+			return nil
 		}
+		g.updatePlayback()
+		return nil
 	}
-}
-
-// ClearFullRows удаляет полностью заполненные ряды
-func (g *Game) ClearFullRows() {
-	var rowsCleared int
-	for y := 0; y < field.Rows; y++ {
-		if g.Field.IsRowFull(y) {
-			g.Field.ClearRow(y)
-			rowsCleared++
+	if g.menu != nil {
+		if g.menu.Update(input.ReadMenuInput()) {
+			g.closeMenu()
 		}
+		return nil
 	}
-	switch rowsCleared {
-	case 1:
-		g.Score += oneLineScore
-	case 2:
-		g.Score += twoLineScore
-	case 3:
-		g.Score += threeLineScore
-	case 4:
-		g.Score += fourLineScore
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.menu = g.newMenu()
+		return nil
 	}
-}
 
-// IsFigureColliding проверяет, сталкивается ли фигура
-func (g *Game) IsFigureColliding() bool {
-	for row := 0; row < 4; row++ {
-		for col := 0; col < 4; col++ {
-			if g.Figure.Cells[row][col] {
-				x := g.Figure.X + col
-				y := g.Figure.Y + row
-				if y >= field.Rows || g.Field.IsOccupied(x, y) {
-					return true
-				}
-			}
+	// Итоги открываются по подтверждению, а до него каждый игрок видит, чем закончилась игра, и может
+	// её перезапустить. Нажатие, которым игра закончилась, подтверждением не считается.
+	if g.over() && input.ReadMenuInput().Confirm {
+		g.finished = true
+		return nil
+	}
+
+	g.inputs.Update()
+	// Один вызов Update соответствует одному тику Ebiten
+	dt := time.Second / time.Duration(ebiten.TPS())
+	for i, p := range g.Players {
+		bits := g.inputs.Player(i).EngineInput().Bits()
+		p.ignored &= bits
+		in := engine.InputFromBits(bits &^ p.ignored)
+		p.Engine.Step(in, dt)
+		if g.recording != nil {
+			g.recording.Record(i, in)
 		}
 	}
-	return false
+	return nil
 }
 
-// Draw отрисовывает игру
+// Draw отрисовывает игру: поля игроков располагаются рядом слева направо
 func (g *Game) Draw(screen *ebiten.Image) {
-	// Отрисовка поля
-	for y := 0; y < field.Rows; y++ {
-		for x := 0; x < field.Cols; x++ {
-			c := emptyCellColor // Серый (пустая клетка)
-			if g.Field.IsOccupied(x, y) {
-				c = occupiedCellColor // Синяя (занятая клетка)
-			}
-			cell := ebiten.NewImage(field.CellSize-2, field.CellSize-2)
-			cell.Fill(c)
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(x*field.CellSize+1), float64(y*field.CellSize+1))
-			screen.DrawImage(cell, op)
+	if g.menu != nil {
+		g.menu.Draw(screen, g.fontFace)
+		return
+	}
+	for i, p := range g.Players {
+		p.canvas.Clear()
+		g.drawPlayer(p.canvas, p.Engine)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(i*playerWidth), 0)
+		screen.DrawImage(p.canvas, op)
+	}
+	if g.playback != nil {
+		g.drawPlaybackBar(screen)
+	}
+}
+
+// drawPlayer отрисовывает поле и боковую панель одного игрока
+func (g *Game) drawPlayer(screen *ebiten.Image, e *engine.Engine) {
+	// Отрисовка видимой части поля
+	b := newBoard(e.Field)
+	for y := e.Field.Buffer; y < e.Field.Rows(); y++ {
+		for x := 0; x < e.Field.Width; x++ {
+			b.drawCell(screen, x, y, cellColor(e.Field.At(x, y)))
 		}
 	}
+	b.drawClearingRows(screen, e)
 
 	// Отрисовка текущей фигуры
-	if !g.GameOver && !g.Paused {
-		for row := 0; row < 4; row++ {
-			for col := 0; col < 4; col++ {
-				if g.Figure.Cells[row][col] {
-					fig := ebiten.NewImage(field.CellSize-2, field.CellSize-2)
-					fig.Fill(figureColor)
-					op := &ebiten.DrawImageOptions{}
-					op.GeoM.Translate(float64((g.Figure.X+col)*field.CellSize+1), float64((g.Figure.Y+row)*field.CellSize+1))
-					screen.DrawImage(fig, op)
-				}
-			}
+	if !e.GameOver && !e.Paused {
+		// Во время очистки линий и задержки появления фигуры на поле нет
+		if e.Phase == engine.PhaseFalling {
+			drawFigure(screen, b, e)
 		}
-	} else if g.Paused {
+		g.drawCallout(screen, e)
+		g.drawReady(screen, e)
+	} else if e.Paused {
 		pausedText := "Paused"
-		text.Draw(screen, pausedText, g.fontFace, field.ScreenWidth/2-(font.MeasureString(g.fontFace, pausedText).Ceil()/2), field.ScreenHeight/2+g.fontFace.Metrics().Ascent.Ceil()/2, textColor)
+		text.Draw(screen, pausedText, g.fontFace, boardWidth/2-(font.MeasureString(g.fontFace, pausedText).Ceil()/2), boardHeight/2+g.fontFace.Metrics().Ascent.Ceil()/2, textColor)
 	} else {
-		// Отрисовка Game Over
-		gameOverText := "Game Over"
-		restartText := "Press R to restart"
+		// Отрисовка Game Over с причиной проигрыша
+		gameOverText := e.Reason.Title()
+		switch {
+		case e.Completed && e.Config.Mode == engine.ModeUltra:
+			gameOverText = fmt.Sprintf("Time up! Score: %d", e.Score)
+		case e.Completed:
+			gameOverText = fmt.Sprintf("Finished: %s", FormatTimer(e.Time))
+		}
+		restartText := fmt.Sprintf("Press %s to restart", g.keyboard.Bindings.Names(input.ActionRestart))
 
 		// Рисуем прямоугольник
 		gameOverRect := ebiten.NewImage(gameOverRectWidth, gameOverRectHeight)
@@ -287,7 +312,13 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		//Текст Game over
 		text.Draw(screen, gameOverText, g.fontFace, gameOverRectX+(gameOverRectWidth/2)-(font.MeasureString(g.fontFace, gameOverText).Ceil()/2), gameOverRectY+(gameOverRectHeight/2), textColor)
 		//Текст restart
-		text.Draw(screen, restartText, g.fontFace, gameOverRectX+(gameOverRectWidth/2)-(font.MeasureString(g.fontFace, restartText).Ceil()/2), gameOverRectY+(gameOverRectHeight/2)+g.fontFace.Metrics().Ascent.Ceil()+g.fontFace.Metrics().Descent.Ceil(), textColor)
+		lineHeight := g.fontFace.Metrics().Ascent.Ceil() + g.fontFace.Metrics().Descent.Ceil()
+		text.Draw(screen, restartText, g.fontFace, gameOverRectX+(gameOverRectWidth/2)-(font.MeasureString(g.fontFace, restartText).Ceil()/2), gameOverRectY+(gameOverRectHeight/2)+lineHeight, textColor)
+		//Переход к итогам, когда закончили все игроки
+		if g.over() {
+			resultsText := "Enter: results"
+			text.Draw(screen, resultsText, g.fontFace, gameOverRectX+(gameOverRectWidth/2)-(font.MeasureString(g.fontFace, resultsText).Ceil()/2), gameOverRectY+(gameOverRectHeight/2)+2*lineHeight, textColor)
+		}
 	}
 	//Рисуем рамку для счета
 	scoreBoard := ebiten.NewImage(scoreBoardWidth, scoreBoardHeight)
@@ -295,9 +326,10 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(float64(scoreBoardX), float64(scoreBoardY))
 	screen.DrawImage(scoreBoard, op)
-	// Отображение очков
-	scoreText := fmt.Sprintf("Score: %d", g.Score)
-	text.Draw(screen, scoreText, g.fontFace, scoreBoardX+10, scoreBoardY+30, textColor)
+	// Отображение очков или показателей режима
+	for i, line := range scoreBoardLines(e) {
+		text.Draw(screen, line, g.fontFace, scoreBoardX+10, scoreBoardY+20+i*scoreBoardLineHeight, textColor)
+	}
 
 	//Рисуем рамку для паузы
 	pauseRect := ebiten.NewImage(pauseRectWidth, pauseRectHeight)
@@ -307,22 +339,125 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	screen.DrawImage(pauseRect, op)
 
 	//Добавляем текст про паузу в прямоугольник
-	pauseText := "Press P for pause"
+	pauseText := fmt.Sprintf("%s: pause, Esc: menu", g.keyboard.Bindings.Names(input.ActionPause))
 	text.Draw(screen, pauseText, g.fontFace, pauseRectX+pauseRectWidth/2-(font.MeasureString(g.fontFace, pauseText).Ceil()/2), pauseRectY+pauseRectHeight/2+g.fontFace.Metrics().Ascent.Ceil()/2, textColor)
 
+	//Рисуем очередь следующих фигур
+	g.drawNextQueue(screen, e)
+	//Рисуем отложенную фигуру
+	g.drawHold(screen, e)
+	//Промежуточные времена в режиме Sprint
+	if e.Config.Mode == engine.ModeSprint {
+		g.drawSplits(screen, e)
+	}
 }
 
-// Layout задает размер экрана
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return field.ScreenWidth + scoreBoardWidth + 10, field.ScreenHeight
+// drawCallout отрисовывает подпись о сложной очистке, комбо или Perfect Clear
+func (g *Game) drawCallout(screen *ebiten.Image, e *engine.Engine) {
+	if e.SinceLastClear > calloutDuration {
+		return
+	}
+	var lines []string
+	if e.LastClear.Difficult() || e.LastClear.TSpin != engine.TSpinNone {
+		lines = append(lines, e.LastClear.String())
+	}
+	if e.LastClear.Combo > 0 {
+		lines = append(lines, fmt.Sprintf("%d Combo", e.LastClear.Combo))
+	}
+	if e.LastClear.PerfectClear {
+		lines = append(lines, "Perfect Clear")
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	calloutRect := ebiten.NewImage(calloutRectWidth, calloutLineHeight*len(lines)+calloutPadding*2)
+	calloutRect.Fill(calloutRectColor)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(calloutRectX), float64(calloutRectY))
+	screen.DrawImage(calloutRect, op)
+
+	for i, calloutText := range lines {
+		text.Draw(screen, calloutText, g.fontFace, calloutRectX+calloutRectWidth/2-(font.MeasureString(g.fontFace, calloutText).Ceil()/2), calloutRectY+calloutPadding+(i+1)*calloutLineHeight-4, textColor)
+	}
 }
 
-// IsFigureCollidingAfterMove проверяет, будет ли столкновение после перемещения на dx, dy
-func (g *Game) IsFigureCollidingAfterMove() bool {
-	return figure.IsFigureCollidingAfterMove(g.Figure, g.Field, 0, 1)
+// lerpColor смешивает цвета from и to в пропорции t (0 - from, 1 - to)
+func lerpColor(from, to color.RGBA, t float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t)
+	}
+	return color.RGBA{mix(from.R, to.R), mix(from.G, to.G), mix(from.B, to.B), mix(from.A, to.A)}
 }
 
-// RestartGame сбрасывает игру
-func (g *Game) RestartGame() {
-	*g = *NewGame()
+// drawNextQueue отрисовывает очередь следующих фигур под табло
+func (g *Game) drawNextQueue(screen *ebiten.Image, e *engine.Engine) {
+	nextRect := ebiten.NewImage(nextRectWidth, nextHeaderHeight+len(e.Next)*nextSlotHeight)
+	nextRect.Fill(nextRectColor)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(nextRectX), float64(nextRectY))
+	screen.DrawImage(nextRect, op)
+
+	nextText := "Next"
+	text.Draw(screen, nextText, g.fontFace, nextRectX+nextRectWidth/2-(font.MeasureString(g.fontFace, nextText).Ceil()/2), nextRectY+nextHeaderHeight-4, textColor)
+
+	for i, shape := range e.Next {
+		drawMiniFigure(screen, shape, nextRectX+nextRectWidth/2, nextRectY+nextHeaderHeight+i*nextSlotHeight+nextSlotHeight/2, shapeColor(shape))
+	}
+}
+
+// drawHold отрисовывает слот отложенной фигуры под очередью
+func (g *Game) drawHold(screen *ebiten.Image, e *engine.Engine) {
+	holdRect := ebiten.NewImage(holdRectWidth, holdRectHeight)
+	holdRect.Fill(holdRectColor)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(holdRectX), float64(holdRectY))
+	screen.DrawImage(holdRect, op)
+
+	holdText := "Hold"
+	text.Draw(screen, holdText, g.fontFace, holdRectX+holdRectWidth/2-(font.MeasureString(g.fontFace, holdText).Ceil()/2), holdRectY+nextHeaderHeight-4, textColor)
+
+	if e.HasHold {
+		c := shapeColor(e.Hold)
+		if e.HoldUsed {
+			c = holdUsedColor
+		}
+		drawMiniFigure(screen, e.Hold, holdRectX+holdRectWidth/2, holdRectY+nextHeaderHeight+nextSlotHeight/2, c)
+	}
+}
+
+// drawMiniFigure отрисовывает уменьшенную фигуру цвета c с центром в точке (centerX, centerY)
+func drawMiniFigure(screen *ebiten.Image, shape models.Shape, centerX, centerY int, c color.Color) {
+	fig := &models.Figure{}
+	figure.SetShape(fig, shape)
+
+	// Находим границы занятых клеток, чтобы выровнять фигуру по центру
+	minRow, maxRow, minCol, maxCol := 4, -1, 4, -1
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			if fig.Cells[row][col] {
+				minRow, maxRow = min(minRow, row), max(maxRow, row)
+				minCol, maxCol = min(minCol, col), max(maxCol, col)
+			}
+		}
+	}
+	originX := centerX - (maxCol-minCol+1)*miniCellSize/2
+	originY := centerY - (maxRow-minRow+1)*miniCellSize/2
+
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			if fig.Cells[row][col] {
+				cell := ebiten.NewImage(miniCellSize-1, miniCellSize-1)
+				cell.Fill(c)
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(originX+(col-minCol)*miniCellSize), float64(originY+(row-minRow)*miniCellSize))
+				screen.DrawImage(cell, op)
+			}
+		}
+	}
+}
+
+// Layout задает размер экрана
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return ScreenSize(len(g.Players))
 }
@@ -0,0 +1,73 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"tetris/internal/savegame"
+	"tetris/internal/ui"
+)
+
+// newMenu создает меню, которое открывается по Esc во время игры
+func (g *Game) newMenu() *ui.Menu {
+	m := &ui.Menu{Title: "Menu", Help: "Up/Down: select  Enter: choose  Esc: resume"}
+	m.Items = append(m.Items, &ui.Item{Label: "Resume", OnSelect: g.closeMenu})
+	if g.saveDir != "" {
+		var slots []*ui.Item
+		for slot := 1; slot <= savegame.Slots; slot++ {
+			item := &ui.Item{Label: fmt.Sprintf("Save slot %d", slot)}
+			item.OnSelect = func() {
+				if err := g.saveGame(savegame.SlotPath(g.saveDir, slot)); err != nil {
+					log.Printf("ошибка при сохранении в слот %d: %v", slot, err)
+					m.Message = fmt.Sprintf("Slot %d: save failed", slot)
+					return
+				}
+				m.Message = fmt.Sprintf("Saved to slot %d", slot)
+				g.describeSlots(slots)
+			}
+			slots = append(slots, item)
+		}
+		for slot := 1; slot <= savegame.Slots; slot++ {
+			item := &ui.Item{Label: fmt.Sprintf("Load slot %d", slot)}
+			item.OnSelect = func() {
+				if err := g.loadGame(savegame.SlotPath(g.saveDir, slot)); err != nil {
+					log.Printf("ошибка при загрузке слота %d: %v", slot, err)
+					m.Message = fmt.Sprintf("Slot %d: load failed", slot)
+					return
+				}
+				g.closeMenu()
+			}
+			slots = append(slots, item)
+		}
+		g.describeSlots(slots)
+		m.Items = append(m.Items, slots...)
+	}
+	// Экран раскладки открывает приложение, игра в это время стоит, а меню остается открытым
+	m.Items = append(m.Items, &ui.Item{Label: "Controls", OnSelect: func() { g.controls = true }})
+	m.Items = append(m.Items, &ui.Item{Label: "Quit to title", OnSelect: func() {
+		g.menu = nil
+		g.quit = true
+	}})
+	return m
+}
+
+// describeSlots подписывает пункты слотов их содержимым: сначала идут пункты сохранения, затем загрузки
+func (g *Game) describeSlots(items []*ui.Item) {
+	for i, item := range items {
+		slot := i%savegame.Slots + 1
+		f, err := savegame.Load(savegame.SlotPath(g.saveDir, slot))
+		if err != nil {
+			item.Detail = "empty"
+			continue
+		}
+		item.Detail = fmt.Sprintf("%s, %s, score %d", f.SavedAt.Format("2006-01-02 15:04"), f.Players[0].Config.Mode.Title(), f.Players[0].Score)
+	}
+}
+
+// closeMenu закрывает меню и возвращается к игре.
+// Клавиши, которыми закрыли меню, игнорируются до отпускания, чтобы они не подействовали на фигуру.
+func (g *Game) closeMenu() {
+	g.menu = nil
+	for _, p := range g.Players {
+		p.ignored = ^uint16(0)
+	}
+}
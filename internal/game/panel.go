@@ -0,0 +1,156 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"tetris/internal/engine"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+const (
+	//Splits
+	splitsRectX      = scoreBoardX
+	splitsRectY      = holdRectY + holdRectHeight + 4
+	splitsRectWidth  = scoreBoardWidth
+	splitsLineHeight = 16 // Строки плотнее, чем на табло, чтобы панель поместилась под hold
+	splitsRectHeight = 2*splitsLineHeight + 4
+	//Ready/Go
+	readyRectWidth  = 120
+	readyRectHeight = 40
+	goDuration      = time.Second // Сколько держится надпись "Go!" после старта
+)
+
+var (
+	splitsRectColor = color.RGBA{200, 200, 200, 255}
+	readyRectColor  = color.RGBA{255, 255, 255, 200}
+	aheadColor      = color.RGBA{0, 120, 0, 255} // Зеленый: быстрее личного рекорда
+	behindColor     = color.RGBA{170, 0, 0, 255} // Красный: медленнее личного рекорда
+)
+
+// scoreBoardLines возвращает строки табло: очки в марафоне, время и темп в спринте, оставшееся время в Ultra,
+// оставшийся мусор в Cheese
+func scoreBoardLines(e *engine.Engine) []string {
+	switch e.Config.Mode {
+	case engine.ModeUltra:
+		return []string{
+			fmt.Sprintf("Left: %s", FormatTimer(e.Remaining())),
+			fmt.Sprintf("Score: %d", e.Score),
+			fmt.Sprintf("Lines: %d", e.Lines),
+			fmt.Sprintf("PPS: %.2f", e.PPS()),
+			fmt.Sprintf("Combo: %d", max(e.Combo, 0)),
+		}
+	case engine.ModeCheese:
+		rise := "Rise: off"
+		if next, ok := e.NextGarbage(); ok {
+			rise = fmt.Sprintf("Rise in: %.1fs", next.Seconds())
+		}
+		return []string{
+			fmt.Sprintf("Time: %s", FormatTimer(e.Time)),
+			fmt.Sprintf("Garbage: %d", e.GarbageLeft),
+			fmt.Sprintf("Pieces: %d", e.Pieces),
+			fmt.Sprintf("PPS: %.2f", e.PPS()),
+			rise,
+		}
+	case engine.ModeSprint:
+		return []string{
+			fmt.Sprintf("Time: %s", FormatTimer(e.Time)),
+			fmt.Sprintf("Lines: %d/%d", e.Lines, e.Config.LineGoal),
+			fmt.Sprintf("PPS: %.2f", e.PPS()),
+			fmt.Sprintf("KPP: %.2f", e.KPP()),
+			fmt.Sprintf("Finesse: %d", e.FinesseFaults),
+		}
+	}
+	b2b := "B2B: -"
+	if e.BackToBack {
+		b2b = "B2B: ready"
+	}
+	return []string{
+		fmt.Sprintf("Score: %d", e.Score),
+		fmt.Sprintf("Level: %d", e.Level),
+		fmt.Sprintf("Lines: %d", e.Lines),
+		fmt.Sprintf("Combo: %d", max(e.Combo, 0)),
+		b2b,
+	}
+}
+
+// drawReady отрисовывает отсчет "Ready" перед стартом и "Go!" в первую секунду игры
+func (g *Game) drawReady(screen *ebiten.Image, e *engine.Engine) {
+	var msg string
+	switch {
+	case e.Ready > 0:
+		msg = "Ready"
+	case e.Config.Mode.ReadyTime() > 0 && e.Time < goDuration:
+		msg = "Go!"
+	default:
+		return
+	}
+	x := (boardWidth - readyRectWidth) / 2
+	y := (boardHeight - readyRectHeight) / 2
+	readyRect := ebiten.NewImage(readyRectWidth, readyRectHeight)
+	readyRect.Fill(readyRectColor)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(readyRect, op)
+	text.Draw(screen, msg, g.fontFace, x+readyRectWidth/2-font.MeasureString(g.fontFace, msg).Ceil()/2, y+readyRectHeight/2+g.fontFace.Metrics().Ascent.Ceil()/2, textColor)
+}
+
+// drawSplits отрисовывает последнее промежуточное время и сравнение с личным рекордом:
+// после отсечки - разницу на ней, а между отсечками - отставание или запас до следующей
+func (g *Game) drawSplits(screen *ebiten.Image, e *engine.Engine) {
+	splitsRect := ebiten.NewImage(splitsRectWidth, splitsRectHeight)
+	splitsRect.Fill(splitsRectColor)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(splitsRectX), float64(splitsRectY))
+	screen.DrawImage(splitsRect, op)
+
+	n := len(e.Splits)
+	last := "Split: -"
+	if n > 0 {
+		last = fmt.Sprintf("%dL: %s", n*10, FormatTimer(e.Splits[n-1]))
+	}
+	text.Draw(screen, last, g.fontFace, splitsRectX+10, splitsRectY+splitsLineHeight-1, textColor)
+
+	var delta time.Duration
+	var compare string
+	switch {
+	case n > 0 && n <= len(g.best) && e.Time-e.Splits[n-1] < calloutDuration:
+		// Сразу после отсечки показываем разницу на ней
+		delta = e.Splits[n-1] - g.best[n-1]
+		compare = fmt.Sprintf("PB %dL: %s", n*10, FormatDelta(delta))
+	case n < len(g.best):
+		// Между отсечками: текущее время против времени рекорда на следующей отсечке
+		delta = e.Time - g.best[n]
+		if delta < 0 {
+			compare = fmt.Sprintf("PB %dL in %s", (n+1)*10, FormatTimer(-delta))
+		} else {
+			compare = fmt.Sprintf("PB %dL: %s", (n+1)*10, FormatDelta(delta))
+		}
+	default:
+		compare = "PB: -"
+	}
+	c := aheadColor
+	if delta > 0 {
+		c = behindColor
+	}
+	text.Draw(screen, compare, g.fontFace, splitsRectX+10, splitsRectY+2*splitsLineHeight-1, c)
+}
+
+// FormatTimer форматирует время с точностью до миллисекунд: 1:02.345
+func FormatTimer(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	return fmt.Sprintf("%d:%02d.%03d", int(d.Minutes()), int(d.Seconds())%60, d.Milliseconds()%1000)
+}
+
+// FormatDelta форматирует разницу с рекордом со знаком: -0.512 или +1.204
+func FormatDelta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	d = d.Round(time.Millisecond)
+	return fmt.Sprintf("%s%d.%03d", sign, int(d.Seconds()), d.Milliseconds()%1000)
+}
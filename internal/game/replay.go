@@ -0,0 +1,111 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"tetris/internal/replay"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+const (
+	fastForwardSpeed = 4               // Во сколько раз ускоряется перемотка вперед
+	seekStep         = 5 * time.Second // Шаг перемотки стрелками
+	replayBarHeight  = 20
+)
+
+var replayBarColor = color.RGBA{255, 255, 255, 255}
+
+// playback - состояние просмотра повтора
+type playback struct {
+	player      *replay.Player // Проигрыватель повтора
+	paused      bool           // Остановлен ли просмотр
+	fastForward bool           // Включена ли ускоренная перемотка
+}
+
+// updatePlayback обрабатывает клавиши просмотра повтора и проигрывает нужное число кадров
+func (g *Game) updatePlayback() {
+	pb := g.playback
+	seekFrames := int(seekStep / pb.player.FrameDuration())
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		pb.paused = !pb.paused
+	case inpututil.IsKeyJustPressed(ebiten.KeyF):
+		pb.fastForward = !pb.fastForward
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		// Покадровый шаг работает на паузе
+		pb.paused = true
+		pb.player.Step()
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+		g.seek(pb.player.Frame - seekFrames)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		g.seek(pb.player.Frame + seekFrames)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		g.seek(0)
+	}
+
+	if !pb.paused {
+		steps := 1
+		if pb.fastForward {
+			steps = fastForwardSpeed
+		}
+		for range steps {
+			pb.player.Step()
+		}
+	}
+	g.syncPlaybackEngines()
+}
+
+// seek перематывает повтор к кадру frame
+func (g *Game) seek(frame int) {
+	if err := g.playback.player.Seek(frame); err != nil {
+		log.Printf("ошибка при перемотке повтора: %v", err)
+	}
+}
+
+// syncPlaybackEngines показывает движки проигрывателя: при перемотке назад они создаются заново
+func (g *Game) syncPlaybackEngines() {
+	for i, p := range g.Players {
+		p.Engine = g.playback.player.Engines[i]
+	}
+}
+
+// drawPlaybackBar отрисовывает строку состояния повтора внизу экрана
+func (g *Game) drawPlaybackBar(screen *ebiten.Image) {
+	pb := g.playback
+	frame := pb.player.FrameDuration()
+	status := fmt.Sprintf("Replay %s / %s", time.Duration(pb.player.Frame)*frame, time.Duration(pb.player.Replay.Frames())*frame)
+	if pb.fastForward {
+		status += fmt.Sprintf(" x%d", fastForwardSpeed)
+	}
+	if pb.paused {
+		status += " [paused]"
+	}
+	if pb.player.Done() {
+		status += " [end]"
+	}
+	status += "  Space F . Left Right Home"
+
+	bar := ebiten.NewImage(screen.Bounds().Dx(), replayBarHeight)
+	bar.Fill(replayBarColor)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(0, float64(boardHeight-replayBarHeight))
+	screen.DrawImage(bar, op)
+	text.Draw(screen, status, g.fontFace, 5, boardHeight-6, textColor)
+}
+
+// SaveRecording сохраняет запись игры, если она ведется
+func (g *Game) SaveRecording() error {
+	if g.recording == nil || g.recordPath == "" {
+		return nil
+	}
+	if err := g.recording.Save(g.recordPath); err != nil {
+		return err
+	}
+	log.Printf("повтор сохранен в %s (%d кадров)", g.recordPath, g.recording.Frames())
+	return nil
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"tetris/internal/engine"
+	"tetris/internal/savegame"
+)
+
+// saveGame сохраняет состояние всех игроков в файл path
+func (g *Game) saveGame(path string) error {
+	engines := make([]*engine.Engine, len(g.Players))
+	for i, p := range g.Players {
+		engines[i] = p.Engine
+	}
+	if err := savegame.Save(path, engines); err != nil {
+		return err
+	}
+	log.Printf("игра сохранена в %s", path)
+	return nil
+}
+
+// loadGame заменяет состояние игроков сохраненным в файле path.
+// Записываемый повтор после этого останавливается: он не может начаться с середины игры.
+func (g *Game) loadGame(path string) error {
+	f, err := savegame.Load(path)
+	if err != nil {
+		return err
+	}
+	if len(f.Players) != len(g.Players) {
+		return fmt.Errorf("в сохранении %d игроков, в текущей игре %d", len(f.Players), len(g.Players))
+	}
+	engines, err := f.Engines()
+	if err != nil {
+		return err
+	}
+	for i, p := range g.Players {
+		p.Engine = engines[i]
+	}
+	if g.recording != nil {
+		log.Printf("запись повтора остановлена: загружено сохранение")
+		g.recording = nil
+	}
+	log.Printf("загружена игра из %s от %s", path, f.SavedAt.Format("2006-01-02 15:04"))
+	return nil
+}
+
+// resumeAutosave продолжает игру, сохраненную при прошлом выходе, если она есть
+func (g *Game) resumeAutosave() error {
+	path := savegame.AutosavePath(g.saveDir)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err := g.loadGame(path); err != nil {
+		return fmt.Errorf("не удалось продолжить сохраненную игру: %w", err)
+	}
+	// Автосохранение использовано: при выходе оно запишется заново, если игра не закончится
+	if err := os.Remove(path); err != nil {
+		log.Printf("не удалось удалить автосохранение: %v", err)
+	}
+	return nil
+}
+
+// SaveOnQuit сохраняет незаконченную игру при выходе, если включено автосохранение.
+// Если все игроки проиграли, прежнее автосохранение удаляется, чтобы не продолжать завершенную игру.
+func (g *Game) SaveOnQuit() error {
+	if !g.autosave || g.playback != nil {
+		return nil
+	}
+	path := savegame.AutosavePath(g.saveDir)
+	for _, p := range g.Players {
+		if !p.Engine.GameOver {
+			return g.saveGame(path)
+		}
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("не удалось удалить автосохранение: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package highscore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"tetris/internal/storage"
+	"time"
+)
+
+const (
+	// MaxEntries - сколько лучших результатов хранится для каждого режима
+	MaxEntries = 10
+	// MaxNameLength - максимальная длина имени игрока
+	MaxNameLength = 12
+	formatVersion = 1
+)
+
+// Entry - один результат в таблице рекордов
+type Entry struct {
+	Name     string          `json:"name"`
+	Score    int             `json:"score"`
+	Lines    int             `json:"lines"`
+	Level    int             `json:"level"`
+	Duration time.Duration   `json:"duration"` // Время игры без учета пауз
+	Pieces   int             `json:"pieces"`   // Сколько фигур зафиксировано
+	Date     time.Time       `json:"date"`
+	Keys     int             `json:"keys,omitempty"`    // Сколько раз нажаты игровые клавиши
+	Finesse  int             `json:"finesse,omitempty"` // Лишние нажатия по сравнению с оптимальной техникой
+	Splits   []time.Duration `json:"splits,omitempty"`  // Время каждого десятка линий
+}
+
+// Order задает, какой результат в таблице лучше
+type Order int
+
+const (
+	ByScore Order = iota // ByScore - Больше очков, при равенстве меньше времени
+	ByTime               // ByTime - Меньше времени, при равенстве больше очков
+)
+
+// KPP возвращает среднее количество нажатий на фигуру
+func (e Entry) KPP() float64 {
+	if e.Pieces == 0 {
+		return 0
+	}
+	return float64(e.Keys) / float64(e.Pieces)
+}
+
+// PPS возвращает среднее количество фигур в секунду
+func (e Entry) PPS() float64 {
+	if e.Duration <= 0 {
+		return 0
+	}
+	return float64(e.Pieces) / e.Duration.Seconds()
+}
+
+// valid проверяет, что запись из файла похожа на настоящий результат
+func (e Entry) valid() bool {
+	return e.Score >= 0 && e.Lines >= 0 && e.Level >= 0 && e.Duration >= 0 && e.Pieces >= 0 && e.Keys >= 0 && e.Finesse >= 0
+}
+
+// Table - таблицы рекордов по режимам игры
+type Table struct {
+	Version int                `json:"version"`
+	Modes   map[string][]Entry `json:"modes"`
+}
+
+// NewTable создает пустую таблицу рекордов
+func NewTable() *Table {
+	return &Table{Version: formatVersion, Modes: map[string][]Entry{}}
+}
+
+// DefaultPath возвращает путь к файлу рекордов в каталоге данных игры
+func DefaultPath() (string, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "highscores.json"), nil
+}
+
+// Load читает таблицу рекордов. Если файла нет, возвращается пустая таблица.
+// Поврежденный файл откладывается в сторону с суффиксом .corrupt, чтобы не потерять его
+// и не затереть при следующем сохранении, а игра продолжается с пустой таблицей.
+func Load(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewTable(), nil
+	}
+	if err != nil {
+		return NewTable(), fmt.Errorf("не удалось прочитать рекорды %s: %w", path, err)
+	}
+	t := NewTable()
+	if err := json.Unmarshal(data, t); err != nil || t.Version != formatVersion {
+		if err == nil {
+			err = fmt.Errorf("неизвестная версия формата %d", t.Version)
+		}
+		backup := path + ".corrupt"
+		if renameErr := os.Rename(path, backup); renameErr != nil {
+			log.Printf("не удалось отложить поврежденный файл рекордов: %v", renameErr)
+		}
+		return NewTable(), fmt.Errorf("файл рекордов %s поврежден и перенесен в %s: %w", path, backup, err)
+	}
+	if t.Modes == nil {
+		t.Modes = map[string][]Entry{}
+	}
+	// Отбрасываем записи, которые не могли появиться в игре
+	for mode, entries := range t.Modes {
+		entries = slices.DeleteFunc(entries, func(e Entry) bool { return !e.valid() })
+		t.Modes[mode] = entries[:min(len(entries), MaxEntries)]
+	}
+	return t, nil
+}
+
+// Save атомарно записывает таблицу рекордов
+func (t *Table) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось закодировать рекорды: %w", err)
+	}
+	if err := storage.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("не удалось сохранить рекорды: %w", err)
+	}
+	return nil
+}
+
+// Top возвращает лучшие результаты режима, от лучшего к худшему
+func (t *Table) Top(mode string) []Entry {
+	return t.Modes[mode]
+}
+
+// Best возвращает лучший результат режима
+func (t *Table) Best(mode string) (Entry, bool) {
+	entries := t.Modes[mode]
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+	return entries[0], true
+}
+
+// Qualifies проверяет, попадет ли результат в таблицу режима с порядком order
+func (t *Table) Qualifies(mode string, order Order, e Entry) bool {
+	entries := t.Modes[mode]
+	return len(entries) < MaxEntries || order.better(e, entries[len(entries)-1])
+}
+
+// Add добавляет результат в таблицу режима с порядком order и возвращает его место (с 0),
+// или -1, если он не попал в таблицу
+func (t *Table) Add(mode string, order Order, e Entry) int {
+	if !t.Qualifies(mode, order, e) {
+		return -1
+	}
+	e.Name = CleanName(e.Name)
+	entries := t.Modes[mode]
+	rank, _ := slices.BinarySearchFunc(entries, e, func(a, b Entry) int {
+		// Новый результат встает после равных ему, чтобы ранний рекорд остался выше
+		if order.better(b, a) {
+			return 1
+		}
+		return -1
+	})
+	entries = slices.Insert(entries, rank, e)
+	t.Modes[mode] = entries[:min(len(entries), MaxEntries)]
+	return rank
+}
+
+// CleanName обрезает имя игрока и подставляет имя по умолчанию вместо пустого
+func CleanName(name string) string {
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > MaxNameLength {
+		name = string(runes[:MaxNameLength])
+	}
+	if name == "" {
+		return "Player"
+	}
+	return name
+}
+
+// better сообщает, что результат a лучше результата b
+func (o Order) better(a, b Entry) bool {
+	if o == ByTime && a.Duration != b.Duration {
+		return a.Duration < b.Duration
+	}
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Duration < b.Duration
+}
@@ -0,0 +1,61 @@
+package input
+
+import "fmt"
+
+// Action представляет логическое действие игрока, не зависящее от конкретной клавиши
+type Action int
+
+const (
+	ActionLeft      Action = iota // ActionLeft - Сдвиг влево
+	ActionRight                   // ActionRight - Сдвиг вправо
+	ActionSoftDrop                // ActionSoftDrop - Ускоренное падение
+	ActionHardDrop                // ActionHardDrop - Мгновенный сброс
+	ActionRotateCW                // ActionRotateCW - Поворот по часовой стрелке
+	ActionRotateCCW               // ActionRotateCCW - Поворот против часовой стрелки
+	ActionRotate180               // ActionRotate180 - Поворот на 180°
+	ActionHold                    // ActionHold - Отложить фигуру
+	ActionPause                   // ActionPause - Пауза
+	ActionRestart                 // ActionRestart - Перезапуск
+	ActionCount                   // ActionCount - Количество действий
+)
+
+// actionNames - имена действий в файле настроек
+var actionNames = [ActionCount]string{
+	ActionLeft:      "left",
+	ActionRight:     "right",
+	ActionSoftDrop:  "soft_drop",
+	ActionHardDrop:  "hard_drop",
+	ActionRotateCW:  "rotate_cw",
+	ActionRotateCCW: "rotate_ccw",
+	ActionRotate180: "rotate_180",
+	ActionHold:      "hold",
+	ActionPause:     "pause",
+	ActionRestart:   "restart",
+}
+
+// String возвращает имя действия
+func (a Action) String() string {
+	if a < 0 || a >= ActionCount {
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+	return actionNames[a]
+}
+
+// MarshalText возвращает имя действия для JSON
+func (a Action) MarshalText() ([]byte, error) {
+	if a < 0 || a >= ActionCount {
+		return nil, fmt.Errorf("неизвестное действие: %d", int(a))
+	}
+	return []byte(actionNames[a]), nil
+}
+
+// UnmarshalText разбирает имя действия из JSON
+func (a *Action) UnmarshalText(text []byte) error {
+	for i, name := range actionNames {
+		if name == string(text) {
+			*a = Action(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("неизвестное действие: %q", text)
+}
@@ -0,0 +1,115 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"tetris/internal/storage"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Bindings сопоставляет каждому действию набор клавиш
+type Bindings map[Action][]ebiten.Key
+
+// DefaultBindings возвращает раскладку клавиатуры по умолчанию
+func DefaultBindings() Bindings {
+	return Bindings{
+		ActionLeft:      {ebiten.KeyArrowLeft},
+		ActionRight:     {ebiten.KeyArrowRight},
+		ActionSoftDrop:  {ebiten.KeyArrowDown},
+		ActionHardDrop:  {ebiten.KeySpace},
+		ActionRotateCW:  {ebiten.KeyArrowUp, ebiten.KeyX},
+		ActionRotateCCW: {ebiten.KeyZ},
+		ActionRotate180: {ebiten.KeyA},
+		ActionHold:      {ebiten.KeyC, ebiten.KeyShiftLeft},
+		ActionPause:     {ebiten.KeyP},
+		ActionRestart:   {ebiten.KeyR},
+	}
+}
+
+// DefaultBindingsPath возвращает путь к файлу раскладки в каталоге настроек пользователя
+func DefaultBindingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить каталог настроек: %w", err)
+	}
+	return filepath.Join(dir, "tetris", "bindings.json"), nil
+}
+
+// LoadBindings читает раскладку из JSON-файла.
+// Если файла нет, возвращается раскладка по умолчанию; действия, не указанные в файле, тоже берутся по умолчанию.
+func LoadBindings(path string) (Bindings, error) {
+	b := DefaultBindings()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return b, fmt.Errorf("не удалось прочитать раскладку %s: %w", path, err)
+	}
+	var loaded Bindings
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return b, fmt.Errorf("неверный формат раскладки %s: %w", path, err)
+	}
+	for action, keys := range loaded {
+		b[action] = keys
+	}
+	return b, nil
+}
+
+// Save записывает раскладку в JSON-файл. Запись атомарная, чтобы сбой не испортил прежнюю раскладку.
+func (b Bindings) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить раскладку: %w", err)
+	}
+	if err := storage.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("не удалось записать раскладку: %w", err)
+	}
+	return nil
+}
+
+// Bind назначает действию a единственную клавишу key. Если клавиша была занята другим действием,
+// она у него отбирается, а если у того действия не осталось клавиш, оно получает прежние клавиши a.
+// Возвращает действие, у которого отобрана клавиша, и признак того, что такое было.
+func (b Bindings) Bind(a Action, key ebiten.Key) (Action, bool) {
+	prev := b[a]
+	b[a] = []ebiten.Key{key}
+	for other, keys := range b {
+		if other == a || !slices.Contains(keys, key) {
+			continue
+		}
+		keys = slices.DeleteFunc(slices.Clone(keys), func(k ebiten.Key) bool { return k == key })
+		if len(keys) == 0 {
+			keys = slices.DeleteFunc(slices.Clone(prev), func(k ebiten.Key) bool { return k == key })
+		}
+		b[other] = keys
+		return other, true
+	}
+	return a, false
+}
+
+// Names возвращает названия клавиш действия через запятую
+func (b Bindings) Names(a Action) string {
+	names := make([]string, len(b[a]))
+	for i, key := range b[a] {
+		names[i] = key.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// Pressed проверяет, нажата ли хотя бы одна клавиша действия
+func (b Bindings) Pressed(a Action) bool {
+	for _, key := range b[a] {
+		if ebiten.IsKeyPressed(key) {
+			return true
+		}
+	}
+	return false
+}
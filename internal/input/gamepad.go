@@ -0,0 +1,150 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// stickThreshold - отклонение левого стика, начиная с которого оно считается нажатием крестовины
+const stickThreshold = 0.5
+
+// GamepadBindings сопоставляет каждому действию кнопки геймпада в стандартной раскладке
+type GamepadBindings map[Action][]GamepadButton
+
+// GamepadButton - кнопка стандартной раскладки геймпада с текстовым именем для файла настроек
+type GamepadButton ebiten.StandardGamepadButton
+
+// gamepadButtonNames - имена кнопок стандартной раскладки в файле настроек
+var gamepadButtonNames = map[GamepadButton]string{
+	GamepadButton(ebiten.StandardGamepadButtonRightBottom):      "A",
+	GamepadButton(ebiten.StandardGamepadButtonRightRight):       "B",
+	GamepadButton(ebiten.StandardGamepadButtonRightLeft):        "X",
+	GamepadButton(ebiten.StandardGamepadButtonRightTop):         "Y",
+	GamepadButton(ebiten.StandardGamepadButtonFrontTopLeft):     "LB",
+	GamepadButton(ebiten.StandardGamepadButtonFrontTopRight):    "RB",
+	GamepadButton(ebiten.StandardGamepadButtonFrontBottomLeft):  "LT",
+	GamepadButton(ebiten.StandardGamepadButtonFrontBottomRight): "RT",
+	GamepadButton(ebiten.StandardGamepadButtonCenterLeft):       "Back",
+	GamepadButton(ebiten.StandardGamepadButtonCenterRight):      "Start",
+	GamepadButton(ebiten.StandardGamepadButtonCenterCenter):     "Home",
+	GamepadButton(ebiten.StandardGamepadButtonLeftStick):        "LeftStick",
+	GamepadButton(ebiten.StandardGamepadButtonRightStick):       "RightStick",
+	GamepadButton(ebiten.StandardGamepadButtonLeftTop):          "DPadUp",
+	GamepadButton(ebiten.StandardGamepadButtonLeftBottom):       "DPadDown",
+	GamepadButton(ebiten.StandardGamepadButtonLeftLeft):         "DPadLeft",
+	GamepadButton(ebiten.StandardGamepadButtonLeftRight):        "DPadRight",
+}
+
+// String возвращает имя кнопки
+func (b GamepadButton) String() string {
+	if name, ok := gamepadButtonNames[b]; ok {
+		return name
+	}
+	return fmt.Sprintf("Button(%d)", int(b))
+}
+
+// MarshalText возвращает имя кнопки для JSON
+func (b GamepadButton) MarshalText() ([]byte, error) {
+	name, ok := gamepadButtonNames[b]
+	if !ok {
+		return nil, fmt.Errorf("неизвестная кнопка геймпада: %d", int(b))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText разбирает имя кнопки из JSON
+func (b *GamepadButton) UnmarshalText(text []byte) error {
+	for button, name := range gamepadButtonNames {
+		if name == string(text) {
+			*b = button
+			return nil
+		}
+	}
+	return fmt.Errorf("неизвестная кнопка геймпада: %q", text)
+}
+
+// DefaultGamepadBindings возвращает раскладку геймпада по умолчанию
+func DefaultGamepadBindings() GamepadBindings {
+	button := func(b ebiten.StandardGamepadButton) GamepadButton { return GamepadButton(b) }
+	return GamepadBindings{
+		ActionLeft:      {button(ebiten.StandardGamepadButtonLeftLeft)},
+		ActionRight:     {button(ebiten.StandardGamepadButtonLeftRight)},
+		ActionSoftDrop:  {button(ebiten.StandardGamepadButtonLeftBottom)},
+		ActionHardDrop:  {button(ebiten.StandardGamepadButtonLeftTop)},
+		ActionRotateCW:  {button(ebiten.StandardGamepadButtonRightBottom)},
+		ActionRotateCCW: {button(ebiten.StandardGamepadButtonRightRight)},
+		ActionRotate180: {button(ebiten.StandardGamepadButtonRightTop)},
+		ActionHold:      {button(ebiten.StandardGamepadButtonRightLeft), button(ebiten.StandardGamepadButtonFrontTopLeft), button(ebiten.StandardGamepadButtonFrontTopRight)},
+		ActionPause:     {button(ebiten.StandardGamepadButtonCenterRight)},
+		ActionRestart:   {button(ebiten.StandardGamepadButtonCenterLeft)},
+	}
+}
+
+// GamepadProfiles хранит раскладки для конкретных моделей геймпадов по их SDL ID
+type GamepadProfiles map[string]GamepadBindings
+
+// DefaultGamepadProfilesPath возвращает путь к файлу раскладок геймпадов в каталоге настроек пользователя
+func DefaultGamepadProfilesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить каталог настроек: %w", err)
+	}
+	return filepath.Join(dir, "tetris", "gamepads.json"), nil
+}
+
+// LoadGamepadProfiles читает раскладки геймпадов из JSON-файла. Если файла нет, возвращается пустой набор.
+func LoadGamepadProfiles(path string) (GamepadProfiles, error) {
+	profiles := GamepadProfiles{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return profiles, fmt.Errorf("не удалось прочитать раскладки геймпадов %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return GamepadProfiles{}, fmt.Errorf("неверный формат раскладок геймпадов %s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// For возвращает раскладку для геймпада с заданным SDL ID.
+// Действия, не указанные в профиле, берутся из раскладки по умолчанию.
+func (p GamepadProfiles) For(sdlID string) GamepadBindings {
+	b := DefaultGamepadBindings()
+	for action, buttons := range p[sdlID] {
+		b[action] = buttons
+	}
+	return b
+}
+
+// Gamepad считывает действия с подключенного геймпада по его раскладке
+type Gamepad struct {
+	ID       ebiten.GamepadID // Идентификатор геймпада в Ebiten
+	Bindings GamepadBindings  // Раскладка кнопок
+}
+
+// Held возвращает действия, кнопки которых сейчас нажаты. Левый стик дублирует крестовину.
+func (g *Gamepad) Held() [ActionCount]bool {
+	var held [ActionCount]bool
+	for a := range ActionCount {
+		for _, b := range g.Bindings[a] {
+			if ebiten.IsStandardGamepadButtonPressed(g.ID, ebiten.StandardGamepadButton(b)) {
+				held[a] = true
+				break
+			}
+		}
+	}
+	horizontal := ebiten.StandardGamepadAxisValue(g.ID, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	vertical := ebiten.StandardGamepadAxisValue(g.ID, ebiten.StandardGamepadAxisLeftStickVertical)
+	held[ActionLeft] = held[ActionLeft] || horizontal < -stickThreshold
+	held[ActionRight] = held[ActionRight] || horizontal > stickThreshold
+	held[ActionSoftDrop] = held[ActionSoftDrop] || vertical > stickThreshold
+	return held
+}
@@ -0,0 +1,44 @@
+package input
+
+import "tetris/internal/engine"
+
+// State хранит действия, удерживаемые игроком в текущем кадре. Нажатия и отпускания
+// определяет движок, сравнивая ввод с предыдущим кадром: так они одинаковы в игре и в повторе.
+type State struct {
+	held [ActionCount]bool // Удерживаемые действия в текущем кадре
+}
+
+// Update принимает удерживаемые в этом кадре действия
+func (s *State) Update(held [ActionCount]bool) {
+	s.held = held
+}
+
+// EngineInput переводит удерживаемые действия во входные данные движка
+func (s *State) EngineInput() engine.Input {
+	return engine.Input{
+		Left:      s.held[ActionLeft],
+		Right:     s.held[ActionRight],
+		Down:      s.held[ActionSoftDrop],
+		HardDrop:  s.held[ActionHardDrop],
+		Rotate:    s.held[ActionRotateCW],
+		RotateCCW: s.held[ActionRotateCCW],
+		Rotate180: s.held[ActionRotate180],
+		Hold:      s.held[ActionHold],
+		Pause:     s.held[ActionPause],
+		Restart:   s.held[ActionRestart],
+	}
+}
+
+// Keyboard считывает действия с клавиатуры по раскладке
+type Keyboard struct {
+	Bindings Bindings // Раскладка клавиш
+}
+
+// Held возвращает действия, клавиши которых сейчас нажаты
+func (k *Keyboard) Held() [ActionCount]bool {
+	var held [ActionCount]bool
+	for a := range ActionCount {
+		held[a] = k.Bindings.Pressed(a)
+	}
+	return held
+}
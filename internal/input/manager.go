@@ -0,0 +1,117 @@
+package input
+
+import (
+	"log"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// playerDevices - устройства, назначенные одному локальному игроку
+type playerDevices struct {
+	keyboard bool       // Управляет ли игрок с клавиатуры
+	gamepads []*Gamepad // Назначенные игроку геймпады
+}
+
+// Manager распределяет клавиатуру и геймпады между локальными игроками
+// и отслеживает подключение и отключение геймпадов на лету
+type Manager struct {
+	Keyboard *Keyboard       // Клавиатура (всегда у первого игрока)
+	Profiles GamepadProfiles // Раскладки для конкретных моделей геймпадов
+	players  []playerDevices // Устройства каждого игрока
+	states   []State         // Действия каждого игрока
+	ids      []ebiten.GamepadID
+}
+
+// NewManager создает менеджер ввода для заданного числа игроков
+func NewManager(players int, keyboard *Keyboard, profiles GamepadProfiles) *Manager {
+	m := &Manager{
+		Keyboard: keyboard,
+		Profiles: profiles,
+		players:  make([]playerDevices, players),
+		states:   make([]State, players),
+	}
+	m.players[0].keyboard = true
+	// Геймпады, подключенные до запуска, тоже распределяем между игроками
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		m.connect(id)
+	}
+	return m
+}
+
+// Update обрабатывает подключение геймпадов и обновляет действия всех игроков
+func (m *Manager) Update() {
+	m.ids = inpututil.AppendJustConnectedGamepadIDs(m.ids[:0])
+	for _, id := range m.ids {
+		m.connect(id)
+	}
+	for i := range m.players {
+		m.players[i].gamepads = slices.DeleteFunc(m.players[i].gamepads, func(g *Gamepad) bool {
+			if inpututil.IsGamepadJustDisconnected(g.ID) {
+				log.Printf("геймпад %d отключен от игрока %d", g.ID, i+1)
+				return true
+			}
+			return false
+		})
+	}
+
+	for i, p := range m.players {
+		var held [ActionCount]bool
+		if p.keyboard {
+			held = m.Keyboard.Held()
+		}
+		for _, g := range p.gamepads {
+			for a, h := range g.Held() {
+				held[a] = held[a] || h
+			}
+		}
+		m.states[i].Update(held)
+	}
+}
+
+// Player возвращает действия игрока с номером i (с нуля)
+func (m *Manager) Player(i int) *State {
+	return &m.states[i]
+}
+
+// connect назначает новый геймпад игроку, у которого меньше всего устройств
+func (m *Manager) connect(id ebiten.GamepadID) {
+	if m.assigned(id) {
+		return
+	}
+	if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+		log.Printf("геймпад %d (%s) не поддерживает стандартную раскладку и не используется", id, ebiten.GamepadName(id))
+		return
+	}
+	best := 0
+	for i, p := range m.players {
+		if deviceCount(p) < deviceCount(m.players[best]) {
+			best = i
+		}
+	}
+	g := &Gamepad{ID: id, Bindings: m.Profiles.For(ebiten.GamepadSDLID(id))}
+	m.players[best].gamepads = append(m.players[best].gamepads, g)
+	log.Printf("геймпад %d (%s) назначен игроку %d", id, ebiten.GamepadName(id), best+1)
+}
+
+// assigned проверяет, назначен ли геймпад кому-то из игроков
+func (m *Manager) assigned(id ebiten.GamepadID) bool {
+	for _, p := range m.players {
+		for _, g := range p.gamepads {
+			if g.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deviceCount возвращает количество устройств игрока
+func deviceCount(p playerDevices) int {
+	n := len(p.gamepads)
+	if p.keyboard {
+		n++
+	}
+	return n
+}
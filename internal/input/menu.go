@@ -0,0 +1,42 @@
+package input
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// MenuInput - нажатия для навигации по меню за один кадр
+type MenuInput struct {
+	Up, Down, Left, Right bool
+	Confirm               bool // Выбрать пункт
+	Back                  bool // Вернуться назад
+}
+
+// ReadMenuInput читает навигацию по меню с клавиатуры и всех геймпадов со стандартной раскладкой.
+// Клавиши меню фиксированы и не зависят от игровой раскладки, чтобы из меню нельзя было «потеряться».
+func ReadMenuInput() MenuInput {
+	key := inpututil.IsKeyJustPressed
+	in := MenuInput{
+		Up:      key(ebiten.KeyArrowUp),
+		Down:    key(ebiten.KeyArrowDown),
+		Left:    key(ebiten.KeyArrowLeft),
+		Right:   key(ebiten.KeyArrowRight),
+		Confirm: key(ebiten.KeyEnter) || key(ebiten.KeySpace),
+		Back:    key(ebiten.KeyEscape) || key(ebiten.KeyBackspace),
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		button := func(b ebiten.StandardGamepadButton) bool {
+			return inpututil.IsStandardGamepadButtonJustPressed(id, b)
+		}
+		in.Up = in.Up || button(ebiten.StandardGamepadButtonLeftTop)
+		in.Down = in.Down || button(ebiten.StandardGamepadButtonLeftBottom)
+		in.Left = in.Left || button(ebiten.StandardGamepadButtonLeftLeft)
+		in.Right = in.Right || button(ebiten.StandardGamepadButtonLeftRight)
+		in.Confirm = in.Confirm || button(ebiten.StandardGamepadButtonRightBottom) || button(ebiten.StandardGamepadButtonCenterRight)
+		in.Back = in.Back || button(ebiten.StandardGamepadButtonRightRight)
+	}
+	return in
+}
@@ -15,11 +15,32 @@ const (
 	ShapeZ              // ShapeZ - Фигура "Z" (Z)
 )
 
+// Valid проверяет, что s - одна из семи фигур
+func (s Shape) Valid() bool {
+	return s >= ShapeI && s <= ShapeZ
+}
+
+// Rotation представляет собой состояние поворота фигуры по SRS
+type Rotation int
+
+const (
+	Rotation0 Rotation = iota // Rotation0 - Начальное положение (0)
+	RotationR                 // RotationR - Поворот на 90° по часовой стрелке (R)
+	Rotation2                 // Rotation2 - Поворот на 180° (2)
+	RotationL                 // RotationL - Поворот на 90° против часовой стрелки (L)
+)
+
+// Valid проверяет, что r - одно из четырех состояний поворота
+func (r Rotation) Valid() bool {
+	return r >= Rotation0 && r <= RotationL
+}
+
 // Figure представляет собой фигуру
 type Figure struct {
-	Shape Shape      // Тип фигуры (одна из констант Shape)
-	Cells [4][4]bool // Матрица 4x4 для хранения формы фигуры
-	X, Y  int        // Координаты фигуры (левый верхний угол)
+	Shape    Shape      // Тип фигуры (одна из констант Shape)
+	Cells    [4][4]bool // Матрица 4x4 для хранения формы фигуры
+	X, Y     int        // Координаты фигуры (левый верхний угол)
+	Rotation Rotation   // Текущее состояние поворота (0/R/2/L)
 }
 
 // String возвращает строковое представление типа фигуры для логов
@@ -44,3 +65,20 @@ func (s Shape) String() string {
 		return "UnknownShape"
 	}
 }
+
+// String возвращает строковое представление состояния поворота для логов
+func (r Rotation) String() string {
+	switch r {
+	case Rotation0:
+		return "0"
+	case RotationR:
+		return "R"
+	case Rotation2:
+		return "2"
+	case RotationL:
+		return "L"
+	default:
+		log.Printf("неизвестное состояние поворота: %d", r)
+		return "UnknownRotation"
+	}
+}
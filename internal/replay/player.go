@@ -0,0 +1,75 @@
+package replay
+
+import (
+	"fmt"
+	"tetris/internal/engine"
+	"time"
+)
+
+// Player воспроизводит повтор, заново прогоняя движки с записанным вводом
+type Player struct {
+	Replay  *Replay          // Воспроизводимый повтор
+	Engines []*engine.Engine // Движки игроков в текущем кадре
+	Frame   int              // Номер следующего кадра
+}
+
+// NewPlayer создает проигрыватель, стоящий на первом кадре
+func NewPlayer(r *Replay) (*Player, error) {
+	p := &Player{Replay: r}
+	if err := p.reset(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// reset создает движки заново с настройками повтора
+func (p *Player) reset() error {
+	p.Engines = p.Engines[:0]
+	for i := range p.Replay.Inputs {
+		e, err := engine.NewEngine(p.Replay.PlayerConfig(i))
+		if err != nil {
+			return fmt.Errorf("не удалось воспроизвести повтор: %w", err)
+		}
+		p.Engines = append(p.Engines, e)
+	}
+	p.Frame = 0
+	return nil
+}
+
+// FrameDuration возвращает длительность одного кадра повтора
+func (p *Player) FrameDuration() time.Duration {
+	return time.Second / time.Duration(p.Replay.TPS)
+}
+
+// Done сообщает, что повтор проигран до конца
+func (p *Player) Done() bool {
+	return p.Frame >= p.Replay.Frames()
+}
+
+// Step проигрывает один кадр
+func (p *Player) Step() {
+	if p.Done() {
+		return
+	}
+	for i, e := range p.Engines {
+		if inputs := p.Replay.Inputs[i]; p.Frame < len(inputs) {
+			e.Step(inputs[p.Frame], p.FrameDuration())
+		}
+	}
+	p.Frame++
+}
+
+// Seek переходит к заданному кадру. Движок не умеет идти назад,
+// поэтому при перемотке назад игра прогоняется заново с начала.
+func (p *Player) Seek(frame int) error {
+	frame = min(max(frame, 0), p.Replay.Frames())
+	if frame < p.Frame {
+		if err := p.reset(); err != nil {
+			return err
+		}
+	}
+	for p.Frame < frame {
+		p.Step()
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"tetris/internal/engine"
+	"tetris/internal/storage"
+)
+
+const (
+	magic         = "TTRP" // Сигнатура файла повтора
+	formatVersion = 1      // Версия формата файла
+
+	maxHeaderSize = 64 << 10 // Максимальный размер заголовка в байтах
+	maxPlayers    = 4        // Максимальное количество игроков в повторе
+	maxFrames     = 1 << 22  // Максимальное суммарное количество кадров всех игроков (около 19 часов при 60 кадрах в секунду)
+)
+
+// header - заголовок файла повтора в JSON
+type header struct {
+	FormatVersion int               `json:"format_version"`
+	EngineVersion int               `json:"engine_version"`
+	TPS           int               `json:"tps"`
+	Players       int               `json:"players"`
+	Config        engine.Config     `json:"config"`
+	Handling      []engine.Handling `json:"handling,omitempty"` // Управление каждого игрока; в старых повторах нет
+}
+
+// Replay хранит всё, что нужно для точного воспроизведения игры: настройки с зерном и ввод каждого кадра
+type Replay struct {
+	EngineVersion int               // Версия движка, на которой записан повтор
+	TPS           int               // Кадров в секунду
+	Config        engine.Config     // Настройки игры, включая зерно генератора
+	Handling      []engine.Handling // Настройки управления каждого игрока (пусто - у всех из Config)
+	Inputs        [][]engine.Input  // Ввод каждого игрока по кадрам
+}
+
+// New создает пустой повтор для заданного числа игроков
+func New(cfg engine.Config, tps, players int) *Replay {
+	return &Replay{
+		EngineVersion: engine.Version,
+		TPS:           tps,
+		Config:        cfg,
+		Inputs:        make([][]engine.Input, players),
+	}
+}
+
+// PlayerConfig возвращает настройки игры игрока player с его настройками управления
+func (r *Replay) PlayerConfig(player int) engine.Config {
+	cfg := r.Config
+	if player < len(r.Handling) {
+		cfg.Handling = r.Handling[player]
+	}
+	return cfg
+}
+
+// Record добавляет ввод игрока за очередной кадр
+func (r *Replay) Record(player int, in engine.Input) {
+	r.Inputs[player] = append(r.Inputs[player], in)
+}
+
+// Frames возвращает длину повтора в кадрах
+func (r *Replay) Frames() int {
+	n := 0
+	for _, inputs := range r.Inputs {
+		n = max(n, len(inputs))
+	}
+	return n
+}
+
+// Save записывает повтор в файл. Запись атомарная, чтобы сбой не оставил обрезанный файл.
+func (r *Replay) Save(path string) error {
+	var buf bytes.Buffer
+	if err := r.write(&buf); err != nil {
+		return fmt.Errorf("не удалось закодировать повтор: %w", err)
+	}
+	if err := storage.WriteFileAtomic(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("не удалось записать повтор: %w", err)
+	}
+	return nil
+}
+
+// Load читает повтор из файла и проверяет, что он записан этой версией движка
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть повтор: %w", err)
+	}
+	defer f.Close()
+	r, err := read(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать повтор %s: %w", path, err)
+	}
+	if r.EngineVersion != engine.Version {
+		return nil, fmt.Errorf("повтор %s записан версией движка %d, текущая версия %d", path, r.EngineVersion, engine.Version)
+	}
+	return r, nil
+}
+
+// write кодирует повтор: сигнатура, заголовок в JSON и ввод каждого игрока в виде серий одинаковых масок
+func (r *Replay) write(w io.Writer) error {
+	data, err := json.Marshal(header{
+		FormatVersion: formatVersion,
+		EngineVersion: r.EngineVersion,
+		TPS:           r.TPS,
+		Players:       len(r.Inputs),
+		Config:        r.Config,
+		Handling:      r.Handling,
+	})
+	if err != nil {
+		return err
+	}
+	buf := []byte(magic)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	buf = append(buf, data...)
+	for _, inputs := range r.Inputs {
+		runs := encodeRuns(inputs)
+		buf = binary.AppendUvarint(buf, uint64(len(runs)))
+		for _, run := range runs {
+			buf = binary.AppendUvarint(buf, uint64(run.bits))
+			buf = binary.AppendUvarint(buf, uint64(run.length))
+		}
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// read декодирует повтор, записанный write
+func read(r *bufio.Reader) (*Replay, error) {
+	sig := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, sig); err != nil || string(sig) != magic {
+		return nil, errors.New("это не файл повтора")
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxHeaderSize {
+		return nil, fmt.Errorf("слишком большой заголовок: %d байт", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	var h header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("неверный заголовок: %w", err)
+	}
+	if h.FormatVersion != formatVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия формата: %d", h.FormatVersion)
+	}
+	if h.TPS <= 0 || h.Players <= 0 || h.Players > maxPlayers || len(h.Handling) > h.Players {
+		return nil, fmt.Errorf("неверный заголовок: tps=%d, players=%d, настроек управления %d", h.TPS, h.Players, len(h.Handling))
+	}
+
+	rep := &Replay{EngineVersion: h.EngineVersion, TPS: h.TPS, Config: h.Config, Handling: h.Handling, Inputs: make([][]engine.Input, h.Players)}
+	frames := uint64(0) // Кадров прочитано у всех игроков
+	for p := range h.Players {
+		count, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		// Каждая серия содержит хотя бы один кадр, поэтому серий не может быть больше оставшихся кадров
+		if count > maxFrames-frames {
+			return nil, fmt.Errorf("слишком много серий ввода у игрока %d: %d", p+1, count)
+		}
+		for range count {
+			bits, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			length, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			if bits > math.MaxUint16 {
+				return nil, fmt.Errorf("неверная маска ввода у игрока %d: %#x", p+1, bits)
+			}
+			if length == 0 || length > maxFrames-frames {
+				return nil, fmt.Errorf("неверная длина серии ввода у игрока %d: %d", p+1, length)
+			}
+			frames += length
+			in := engine.InputFromBits(uint16(bits))
+			for range length {
+				rep.Inputs[p] = append(rep.Inputs[p], in)
+			}
+		}
+	}
+	return rep, nil
+}
+
+// run - серия кадров с одинаковым вводом
+type run struct {
+	bits   uint16
+	length int
+}
+
+// encodeRuns сжимает ввод в серии одинаковых масок
+func encodeRuns(inputs []engine.Input) []run {
+	var runs []run
+	for _, in := range inputs {
+		bits := in.Bits()
+		if len(runs) > 0 && runs[len(runs)-1].bits == bits {
+			runs[len(runs)-1].length++
+			continue
+		}
+		runs = append(runs, run{bits: bits, length: 1})
+	}
+	return runs
+}
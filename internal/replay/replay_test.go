@@ -0,0 +1,171 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"tetris/internal/engine"
+	"tetris/internal/figure"
+	"tetris/internal/models"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Движок пишет в журнал каждое движение фигуры
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// testConfig возвращает настройки игры с заданной последовательностью фигур
+func testConfig() engine.Config {
+	cfg := engine.DefaultConfig()
+	cfg.Randomizer = figure.RandomizerSequence
+	cfg.Sequence = []models.Shape{models.ShapeI, models.ShapeT, models.ShapeO, models.ShapeS, models.ShapeZ, models.ShapeL, models.ShapeJ}
+	return cfg
+}
+
+// randomInput возвращает случайное действие игрока; перезапуск начинает новую игру после проигрыша
+func randomInput(rng *rand.Rand) engine.Input {
+	actions := []engine.Input{
+		{}, {Left: true}, {Right: true}, {Rotate: true}, {RotateCCW: true}, {Rotate180: true},
+		{Hold: true}, {Down: true}, {HardDrop: true}, {Restart: true},
+	}
+	return actions[rng.IntN(len(actions))]
+}
+
+// TestRecordPlay записывает игру двух игроков с разными настройками управления, сохраняет повтор
+// в файловом формате, читает обратно и проверяет, что воспроизведение приходит к тем же полям и счету
+func TestRecordPlay(t *testing.T) {
+	const tps, frames = 60, 2000
+	cfg := testConfig()
+	rec := New(cfg, tps, 2)
+	rec.Handling = []engine.Handling{engine.DefaultHandling(), {DAS: 50 * time.Millisecond, SDF: 0}}
+	var live []*engine.Engine
+	for i := range 2 {
+		e, err := engine.NewEngine(rec.PlayerConfig(i))
+		if err != nil {
+			t.Fatalf("не удалось создать движок: %v", err)
+		}
+		live = append(live, e)
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	inputs := make([]engine.Input, len(live))
+	for range frames {
+		for i, e := range live {
+			// Действие каждого игрока удерживается несколько кадров
+			if rng.IntN(5) == 0 {
+				inputs[i] = randomInput(rng)
+			}
+			rec.Record(i, inputs[i])
+			e.Step(inputs[i], time.Second/tps)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := rec.write(&buf); err != nil {
+		t.Fatalf("не удалось записать повтор: %v", err)
+	}
+	loaded, err := read(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("не удалось прочитать повтор: %v", err)
+	}
+	if loaded.Frames() != frames {
+		t.Fatalf("в повторе %d кадров, записано %d", loaded.Frames(), frames)
+	}
+
+	if !slices.Equal(loaded.Handling, rec.Handling) {
+		t.Fatalf("настройки управления в повторе %v, записаны %v", loaded.Handling, rec.Handling)
+	}
+
+	p, err := NewPlayer(loaded)
+	if err != nil {
+		t.Fatalf("не удалось создать проигрыватель: %v", err)
+	}
+	for !p.Done() {
+		p.Step()
+	}
+	for i, e := range p.Engines {
+		want := live[i]
+		if !slices.EqualFunc(e.Field.Cells, want.Field.Cells, slices.Equal) {
+			t.Errorf("игрок %d: поле после воспроизведения отличается от записанного", i+1)
+		}
+		if e.Score != want.Score || e.Pieces != want.Pieces || e.Time != want.Time {
+			t.Errorf("игрок %d: очки %d/%d, фигур %d/%d, время %s/%s", i+1, e.Score, want.Score, e.Pieces, want.Pieces, e.Time, want.Time)
+		}
+	}
+
+	// Перемотка назад прогоняет игру заново и приходит к тому же кадру
+	if err := p.Seek(frames / 2); err != nil {
+		t.Fatalf("не удалось перемотать повтор: %v", err)
+	}
+	if err := p.Seek(frames); err != nil {
+		t.Fatalf("не удалось перемотать повтор: %v", err)
+	}
+	for i, e := range p.Engines {
+		if !slices.EqualFunc(e.Field.Cells, live[i].Field.Cells, slices.Equal) {
+			t.Errorf("игрок %d: поле после перемотки отличается от записанного", i+1)
+		}
+	}
+}
+
+// TestReadLimits проверяет, что размеры из поврежденного файла не принимаются на веру
+func TestReadLimits(t *testing.T) {
+	rec := New(testConfig(), 60, 1)
+	rec.Record(0, engine.Input{})
+	var valid bytes.Buffer
+	if err := rec.write(&valid); err != nil {
+		t.Fatalf("не удалось записать повтор: %v", err)
+	}
+	// Начало файла до серий ввода единственного игрока
+	head := valid.Bytes()[:valid.Len()-3]
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"header size", binary.AppendUvarint([]byte(magic), maxHeaderSize+1)},
+		{"run count", binary.AppendUvarint(slices.Clone(head), maxFrames+1)},
+		{"run length", binary.AppendUvarint(binary.AppendUvarint(binary.AppendUvarint(slices.Clone(head), 1), 0), maxFrames+1)},
+		{"empty run", binary.AppendUvarint(binary.AppendUvarint(binary.AppendUvarint(slices.Clone(head), 1), 0), 0)},
+		{"input bits", binary.AppendUvarint(binary.AppendUvarint(binary.AppendUvarint(slices.Clone(head), 1), 1<<16), 1)},
+	}
+	for _, tc := range tests {
+		if _, err := read(bufio.NewReader(bytes.NewReader(tc.data))); err == nil {
+			t.Errorf("%s: поврежденный повтор прочитан без ошибки", tc.name)
+		}
+	}
+	if _, err := read(bufio.NewReader(bytes.NewReader(valid.Bytes()))); err != nil {
+		t.Fatalf("не удалось прочитать целый повтор: %v", err)
+	}
+}
+
+// TestSaveLoad проверяет запись повтора в файл и чтение обратно
+func TestSaveLoad(t *testing.T) {
+	rec := New(testConfig(), 60, 1)
+	for range 100 {
+		rec.Record(0, engine.Input{Left: true})
+	}
+	path := filepath.Join(t.TempDir(), "game.ttrp")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("не удалось сохранить повтор: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("не удалось загрузить повтор: %v", err)
+	}
+	if !slices.Equal(loaded.Inputs[0], rec.Inputs[0]) {
+		t.Fatalf("ввод в загруженном повторе отличается от записанного")
+	}
+	// Рядом с повтором не остается временных файлов
+	if files, _ := os.ReadDir(filepath.Dir(path)); len(files) != 1 {
+		t.Fatalf("в каталоге повтора %d файлов, ожидался один", len(files))
+	}
+}
@@ -0,0 +1,106 @@
+package savegame
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"tetris/internal/engine"
+	"tetris/internal/storage"
+	"time"
+)
+
+const (
+	// FormatVersion - версия формата файла сохранения
+	FormatVersion = 1
+	// Slots - количество слотов ручного сохранения
+	Slots = 3
+)
+
+// File - сохраненная игра всех локальных игроков
+type File struct {
+	FormatVersion int               `json:"format_version"`
+	SavedAt       time.Time         `json:"saved_at"`
+	Players       []engine.Snapshot `json:"players"`
+}
+
+// DefaultDir возвращает каталог сохранений в каталоге данных игры
+func DefaultDir() (string, error) {
+	dir, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "saves"), nil
+}
+
+// SlotPath возвращает путь к файлу слота сохранения (слоты нумеруются с 1)
+func SlotPath(dir string, slot int) string {
+	return filepath.Join(dir, fmt.Sprintf("slot%d.json", slot))
+}
+
+// AutosavePath возвращает путь к файлу, в который игра сохраняется при выходе
+func AutosavePath(dir string) string {
+	return filepath.Join(dir, "autosave.json")
+}
+
+// Save сохраняет состояние движков в файл. Запись атомарная, чтобы сбой не испортил прежнее сохранение.
+func Save(path string, engines []*engine.Engine) error {
+	f := File{FormatVersion: FormatVersion, SavedAt: time.Now()}
+	for _, e := range engines {
+		s, err := e.Snapshot()
+		if err != nil {
+			return err
+		}
+		f.Players = append(f.Players, s)
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось закодировать сохранение: %w", err)
+	}
+	if err := storage.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("не удалось записать сохранение: %w", err)
+	}
+	return nil
+}
+
+// Load читает файл сохранения и проверяет его версию
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать сохранение: %w", err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("неверный формат сохранения %s: %w", path, err)
+	}
+	if f.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("сохранение %s имеет версию формата %d, поддерживается %d", path, f.FormatVersion, FormatVersion)
+	}
+	if len(f.Players) == 0 {
+		return nil, fmt.Errorf("в сохранении %s нет игроков", path)
+	}
+	return &f, nil
+}
+
+// SetAside откладывает сохранение, которое не удалось продолжить, в сторону с суффиксом .corrupt:
+// оно больше не загружается, но и не теряется. Возвращает новый путь к файлу.
+func SetAside(path string) (string, error) {
+	backup := path + ".corrupt"
+	if err := os.Rename(path, backup); err != nil {
+		return "", fmt.Errorf("не удалось отложить сохранение %s: %w", path, err)
+	}
+	return backup, nil
+}
+
+// Engines восстанавливает движки игроков из сохранения
+func (f *File) Engines() ([]*engine.Engine, error) {
+	engines := make([]*engine.Engine, 0, len(f.Players))
+	for i, s := range f.Players {
+		e, err := engine.Restore(s)
+		if err != nil {
+			return nil, fmt.Errorf("игрок %d: %w", i+1, err)
+		}
+		engines = append(engines, e)
+	}
+	return engines, nil
+}
@@ -0,0 +1,108 @@
+package scene
+
+import (
+	"fmt"
+	"log"
+	"tetris/internal/input"
+	"tetris/internal/ui"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// controlsScene - экран переназначения клавиш
+type controlsScene struct {
+	manager  *Manager
+	back     Scene        // Экран, на который нужно вернуться
+	selected input.Action // Выбранное действие
+	waiting  bool         // Ждем нажатия новой клавиши для выбранного действия
+	message  string       // Результат последнего переназначения
+	keys     []ebiten.Key // Буфер для только что нажатых клавиш
+}
+
+// newControls создает экран раскладки, возвращающийся на экран back
+func newControls(m *Manager, back Scene) Scene {
+	return &controlsScene{manager: m, back: back}
+}
+
+// Update обрабатывает навигацию по экрану раскладки с клавиатуры и геймпада и назначение клавиш
+func (s *controlsScene) Update() error {
+	bindings := s.manager.keyboard.Bindings
+	in := input.ReadMenuInput()
+	if s.waiting {
+		s.keys = inpututil.AppendJustPressedKeys(s.keys[:0])
+		for _, key := range s.keys {
+			s.waiting = false
+			if key == ebiten.KeyEscape {
+				return nil
+			}
+			s.bind(key)
+			return nil
+		}
+		// Геймпадом клавишу не назначить, но можно отменить ожидание
+		if in.Back {
+			s.waiting = false
+		}
+		return nil
+	}
+
+	switch {
+	case in.Back:
+		if path := s.manager.opts.BindingsPath; path != "" {
+			if err := bindings.Save(path); err != nil {
+				log.Printf("ошибка при сохранении раскладки: %v", err)
+			}
+		}
+		s.manager.switchTo(s.back)
+	case in.Up:
+		s.selected = (s.selected + input.ActionCount - 1) % input.ActionCount
+		s.message = ""
+	case in.Down:
+		s.selected = (s.selected + 1) % input.ActionCount
+		s.message = ""
+	case in.Confirm:
+		s.waiting = true
+		s.message = ""
+	case inpututil.IsKeyJustPressed(ebiten.KeyDelete):
+		bindings[s.selected] = input.DefaultBindings()[s.selected]
+		s.message = fmt.Sprintf("%s: default keys", s.selected)
+	}
+	return nil
+}
+
+// bind назначает выбранному действию клавишу key. Клавиша, занятая другим действием,
+// переходит к выбранному, а другое действие при необходимости получает его прежние клавиши.
+func (s *controlsScene) bind(key ebiten.Key) {
+	bindings := s.manager.keyboard.Bindings
+	other, taken := bindings.Bind(s.selected, key)
+	log.Printf("действию %s назначена клавиша %s", s.selected, key)
+	s.message = fmt.Sprintf("%s: %s", s.selected, key)
+	if taken {
+		log.Printf("клавиша %s снята с действия %s, у него теперь %s", key, other, bindings.Names(other))
+		s.message = fmt.Sprintf("%s moved from %s (now %s)", key, other, bindings.Names(other))
+	}
+}
+
+// Draw отрисовывает список действий и назначенных клавиш
+func (s *controlsScene) Draw(screen *ebiten.Image) {
+	face := s.manager.fontFace
+	bindings := s.manager.keyboard.Bindings
+	ui.DrawBackground(screen)
+	text.Draw(screen, "Controls", face, ui.MarginX, ui.MarginY, ui.TextColor)
+	for a := range input.ActionCount {
+		y := ui.MarginY + (int(a)+1)*ui.LineHeight
+		if a == s.selected {
+			ui.DrawHighlight(screen, y)
+		}
+		keys := "press a key..."
+		if a != s.selected || !s.waiting {
+			keys = bindings.Names(a)
+		}
+		text.Draw(screen, fmt.Sprintf("%-12s %s", a, keys), face, ui.MarginX, y, ui.TextColor)
+	}
+	if s.message != "" {
+		text.Draw(screen, s.message, face, ui.MarginX, ui.MarginY+(int(input.ActionCount)+2)*ui.LineHeight, ui.TextColor)
+	}
+	ui.DrawHelp(screen, face, "Enter: rebind  Delete: default  Esc: save and back")
+}
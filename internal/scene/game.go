@@ -0,0 +1,37 @@
+package scene
+
+import (
+	"tetris/internal/game"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// gameScene - экран идущей игры или просмотра повтора
+type gameScene struct {
+	manager *Manager
+	game    *game.Game
+}
+
+// Update продвигает игру и по её окончании открывает итоги
+func (s *gameScene) Update() error {
+	if err := s.game.Update(); err != nil {
+		return err
+	}
+	switch {
+	case s.game.ControlsRequested():
+		// Раскладка меняется прямо во время игры и действует сразу после возвращения
+		s.manager.switchTo(newControls(s.manager, s))
+	case s.game.QuitRequested():
+		s.manager.endGame()
+		s.manager.switchTo(newTitle(s.manager))
+	case s.game.Finished():
+		s.manager.endGame()
+		s.manager.switchTo(newResults(s.manager, s.game))
+	}
+	return nil
+}
+
+// Draw отрисовывает игру
+func (s *gameScene) Draw(screen *ebiten.Image) {
+	s.game.Draw(screen)
+}
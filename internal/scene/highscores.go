@@ -0,0 +1,144 @@
+package scene
+
+import (
+	"fmt"
+	"slices"
+	"tetris/internal/engine"
+	"tetris/internal/game"
+	"tetris/internal/highscore"
+	"tetris/internal/input"
+	"tetris/internal/ui"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// scoresScene - таблица рекордов; категории переключаются стрелками влево/вправо
+type scoresScene struct {
+	manager   *Manager
+	list      []engine.Config // Категории таблицы
+	category  int             // Индекс в list
+	highlight int             // Место только что добавленного результата (-1 - нет)
+	back      Scene           // Экран, на который нужно вернуться
+}
+
+// newScores создает экран рекордов категории режима cfg с подсвеченным местом highlight.
+// Показываются категории с теми же задержками очистки линий и появления фигуры, что и в cfg.
+func newScores(m *Manager, cfg engine.Config, highlight int, back Scene) Scene {
+	list := categories(cfg)
+	category := max(slices.IndexFunc(list, func(c engine.Config) bool {
+		return c.Category() == cfg.Category()
+	}), 0)
+	return &scoresScene{manager: m, list: list, category: category, highlight: highlight, back: back}
+}
+
+// categories возвращает категории таблицы рекордов с задержками из base: по одной на режим,
+// на каждую цель спринта, длительность Ultra и количество мусора Cheese
+func categories(base engine.Config) []engine.Config {
+	var list []engine.Config
+	for _, mode := range engine.Modes {
+		cfg := engine.DefaultConfig()
+		cfg.Mode = mode
+		cfg.LineClearDelay, cfg.EntryDelay = base.LineClearDelay, base.EntryDelay
+		switch mode {
+		case engine.ModeSprint:
+			for _, goal := range engine.SprintGoals {
+				cfg.LineGoal = goal
+				list = append(list, cfg)
+			}
+		case engine.ModeUltra:
+			for _, limit := range engine.UltraTimes {
+				cfg.TimeLimit = limit
+				list = append(list, cfg)
+			}
+		case engine.ModeCheese:
+			for _, rows := range engine.CheeseRows {
+				cfg.GarbageRows = rows
+				list = append(list, cfg)
+			}
+		default:
+			list = append(list, cfg)
+		}
+	}
+	return list
+}
+
+// orderFor возвращает порядок результатов в таблице режима: в гонках соревнуются во времени, в остальных - в очках
+func orderFor(cfg engine.Config) highscore.Order {
+	if cfg.Mode.Race() {
+		return highscore.ByTime
+	}
+	return highscore.ByScore
+}
+
+// Update обрабатывает переключение категорий и возврат
+func (s *scoresScene) Update() error {
+	in := input.ReadMenuInput()
+	n := len(s.list)
+	switch {
+	case in.Back, in.Confirm:
+		s.manager.switchTo(s.back)
+	case in.Left:
+		s.category = (s.category + n - 1) % n
+		s.highlight = -1
+	case in.Right:
+		s.category = (s.category + 1) % n
+		s.highlight = -1
+	}
+	return nil
+}
+
+// Draw отрисовывает таблицу рекордов
+func (s *scoresScene) Draw(screen *ebiten.Image) {
+	face := s.manager.fontFace
+	cfg := s.list[s.category]
+	ui.DrawBackground(screen)
+	title := cfg.Title()
+	if def := engine.DefaultConfig(); cfg.LineClearDelay != def.LineClearDelay || cfg.EntryDelay != def.EntryDelay {
+		title += fmt.Sprintf(" (clear %s, ARE %s)", cfg.LineClearDelay, cfg.EntryDelay)
+	}
+	text.Draw(screen, fmt.Sprintf("High scores: < %s >", title), face, ui.MarginX, ui.MarginY, ui.TextColor)
+	header := fmt.Sprintf("%2s %-12s %7s %5s %3s %5s %4s %s", "#", "Name", "Score", "Lines", "Lv", "Time", "PPS", "Date")
+	if cfg.Mode.Race() {
+		header = fmt.Sprintf("%2s %-12s %9s %6s %4s %4s %3s %s", "#", "Name", "Time", "Pieces", "PPS", "KPP", "Fin", "Date")
+	}
+	text.Draw(screen, header, face, ui.MarginX, ui.MarginY+ui.LineHeight, ui.TextColor)
+	entries := s.manager.scores.Top(cfg.Category())
+	if len(entries) == 0 {
+		text.Draw(screen, "No results yet", face, ui.MarginX, ui.MarginY+2*ui.LineHeight, ui.TextColor)
+	}
+	for i, e := range entries {
+		y := ui.MarginY + (i+2)*ui.LineHeight
+		if i == s.highlight {
+			ui.DrawHighlight(screen, y)
+		}
+		line := fmt.Sprintf("%2d %-12s %7d %5d %3d %5s %4.2f %s", i+1, e.Name, e.Score, e.Lines, e.Level, formatClock(e.Duration), e.PPS(), e.Date.Format("2006-01-02"))
+		if cfg.Mode.Race() {
+			line = fmt.Sprintf("%2d %-12s %9s %6d %4.2f %4.2f %3d %s", i+1, e.Name, game.FormatTimer(e.Duration), e.Pieces, e.PPS(), e.KPP(), e.Finesse, e.Date.Format("2006-01-02"))
+		}
+		text.Draw(screen, line, face, ui.MarginX, y, ui.TextColor)
+	}
+	ui.DrawHelp(screen, face, "Left/Right: mode  Esc: back")
+}
+
+// formatClock форматирует длительность как минуты и секунды
+func formatClock(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// entryFor составляет запись для таблицы рекордов по итогам игры
+func entryFor(e *engine.Engine) highscore.Entry {
+	return highscore.Entry{
+		Score:    e.Score,
+		Lines:    e.Lines,
+		Level:    e.Level,
+		Duration: e.Time,
+		Pieces:   e.Pieces,
+		Date:     time.Now(),
+		Keys:     e.Keys,
+		Finesse:  e.FinesseFaults,
+		Splits:   slices.Clone(e.Splits),
+	}
+}
@@ -0,0 +1,82 @@
+package scene
+
+import (
+	"strconv"
+	"tetris/internal/engine"
+	"tetris/internal/settings"
+	"tetris/internal/ui"
+)
+
+// newModeSelect создает экран выбора режима; у режимов с параметрами они меняются стрелками влево/вправо
+func newModeSelect(m *Manager) Scene {
+	menu := &ui.Menu{Title: "Select mode"}
+	for _, mode := range engine.Modes {
+		item := &ui.Item{Label: mode.Title(), OnSelect: func() {
+			m.saveSettings()
+			m.startGame(mode)
+		}}
+		switch mode {
+		case engine.ModeMarathon:
+			// Параметр марафона - начальный уровень
+			for level := engine.MinStartLevel; level <= engine.MaxStartLevel; level++ {
+				item.Values = append(item.Values, "level "+strconv.Itoa(level))
+			}
+			item.Index = m.settings.StartLevel - engine.MinStartLevel
+			item.OnChange = func(i int) { m.changeSettings(func(s *settings.Settings) { s.StartLevel = engine.MinStartLevel + i }) }
+		case engine.ModeSprint:
+			// Параметр спринта - сколько линий нужно очистить
+			for i, goal := range engine.SprintGoals {
+				item.Values = append(item.Values, strconv.Itoa(goal)+" lines")
+				if goal == m.settings.LineGoal {
+					item.Index = i
+				}
+			}
+			item.OnChange = func(i int) { m.changeSettings(func(s *settings.Settings) { s.LineGoal = engine.SprintGoals[i] }) }
+		case engine.ModeUltra:
+			// Параметр Ultra - длительность игры
+			for i, limit := range engine.UltraTimes {
+				item.Values = append(item.Values, strconv.Itoa(int(limit.Minutes()))+" min")
+				if limit == m.settings.TimeLimit {
+					item.Index = i
+				}
+			}
+			item.OnChange = func(i int) { m.changeSettings(func(s *settings.Settings) { s.TimeLimit = engine.UltraTimes[i] }) }
+		case engine.ModeCheese:
+			// Параметр Cheese - количество мусорных рядов из тех, что помещаются на поле;
+			// беспорядок и подъем мусора - в настройках
+			choices := cheeseChoices(m.settings.Height)
+			for _, rows := range choices {
+				item.Values = append(item.Values, strconv.Itoa(rows)+" rows")
+			}
+			item.Index = cheeseIndex(choices, m.settings.GarbageRows)
+			item.OnChange = func(i int) { m.changeSettings(func(s *settings.Settings) { s.GarbageRows = choices[i] }) }
+		}
+		menu.Items = append(menu.Items, item)
+	}
+	back := func() { m.switchTo(newTitle(m)) }
+	menu.Items = append(menu.Items, &ui.Item{Label: "Back", OnSelect: back})
+	return &menuScene{manager: m, menu: menu, back: back}
+}
+
+// cheeseChoices возвращает варианты количества мусорных рядов, которые помещаются на поле высотой height
+func cheeseChoices(height int) []int {
+	var choices []int
+	for _, rows := range engine.CheeseRows {
+		if rows <= engine.MaxGarbageRows(height) {
+			choices = append(choices, rows)
+		}
+	}
+	return choices
+}
+
+// cheeseIndex возвращает вариант, который показывается для rows мусорных рядов:
+// наибольший из вариантов не больше rows, а если таких нет - первый
+func cheeseIndex(choices []int, rows int) int {
+	index := 0
+	for i, c := range choices {
+		if c <= rows {
+			index = i
+		}
+	}
+	return index
+}
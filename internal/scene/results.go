@@ -0,0 +1,211 @@
+package scene
+
+import (
+	"fmt"
+	"log"
+	"tetris/internal/engine"
+	"tetris/internal/game"
+	"tetris/internal/highscore"
+	"tetris/internal/input"
+	"tetris/internal/ui"
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// pendingScore - рекорд, для которого еще не введено имя
+type pendingScore struct {
+	player int
+	entry  highscore.Entry
+}
+
+// resultsScene - итоги законченной игры: ввод имени для рекордов, статистика и выбор, что дальше
+type resultsScene struct {
+	manager   *Manager
+	cfg       engine.Config // Параметры законченной игры: режим и его цель
+	engines   []*engine.Engine
+	pending   []pendingScore  // Рекорды, ожидающие ввода имени, по порядку игроков
+	name      []rune          // Вводимое имя
+	chars     []rune          // Буфер для введенных за кадр символов
+	highlight int             // Место последнего добавленного рекорда (-1 - нет)
+	best      []time.Duration // Промежуточные времена рекорда, каким он был до этой игры
+	menu      *ui.Menu
+}
+
+// newResults создает экран итогов игры g
+func newResults(m *Manager, g *game.Game) Scene {
+	s := &resultsScene{manager: m, highlight: -1, name: []rune(m.lastName)}
+	if m.scores != nil {
+		if pb, ok := m.scores.Best(g.Players[0].Engine.Config.Category()); ok {
+			s.best = pb.Splits
+		}
+	}
+	for i, p := range g.Players {
+		s.engines = append(s.engines, p.Engine)
+		s.cfg = p.Engine.Config
+		entry := entryFor(p.Engine)
+		// Незаконченная гонка не считается результатом
+		if s.cfg.Mode.Race() && !p.Engine.Completed {
+			continue
+		}
+		// Результаты на поле нестандартного размера не сравниваются с рекордами
+		if !s.cfg.Ranked() {
+			continue
+		}
+		if m.scores != nil && m.scores.Qualifies(s.cfg.Category(), orderFor(s.cfg), entry) {
+			s.pending = append(s.pending, pendingScore{player: i, entry: entry})
+		}
+	}
+	toTitle := func() { m.switchTo(newTitle(m)) }
+	// У нескольких игроков причины проигрыша разные, они выводятся в колонках
+	title := "Game over"
+	if e := g.Players[0].Engine; e.Completed || len(g.Players) == 1 {
+		title = e.Reason.Title() + "!"
+	}
+	s.menu = &ui.Menu{Title: title, Help: "Up/Down: select  Enter: choose"}
+	s.menu.Items = []*ui.Item{
+		{Label: "Retry", OnSelect: func() { m.startGame(s.cfg.Mode) }},
+		{Label: "Mode select", OnSelect: func() { m.switchTo(newModeSelect(m)) }},
+	}
+	if m.scores != nil {
+		s.menu.Items = append(s.menu.Items, &ui.Item{Label: "High scores", OnSelect: func() {
+			m.switchTo(newScores(m, s.cfg, s.highlight, s))
+		}})
+	}
+	s.menu.Items = append(s.menu.Items, &ui.Item{Label: "Title", OnSelect: toTitle})
+	return s
+}
+
+// Update обрабатывает ввод имени, а после него - меню итогов
+func (s *resultsScene) Update() error {
+	if len(s.pending) > 0 {
+		s.updateName()
+		return nil
+	}
+	if s.menu.Update(input.ReadMenuInput()) {
+		s.manager.switchTo(newTitle(s.manager))
+	}
+	return nil
+}
+
+// updateName обрабатывает ввод имени для первого ожидающего рекорда
+func (s *resultsScene) updateName() {
+	s.chars = ebiten.AppendInputChars(s.chars[:0])
+	for _, r := range s.chars {
+		if unicode.IsPrint(r) && len(s.name) < highscore.MaxNameLength {
+			s.name = append(s.name, r)
+		}
+	}
+	// С геймпада имя не ввести, поэтому A подтверждает прошлое имя, а B пропускает рекорд
+	in := input.ReadMenuInput()
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		if len(s.name) > 0 {
+			s.name = s.name[:len(s.name)-1]
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter), in.Confirm && !inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		s.saveScore()
+	case in.Back:
+		s.pending = s.pending[1:]
+	}
+}
+
+// saveScore добавляет рекорд с введенным именем в таблицу и сохраняет её
+func (s *resultsScene) saveScore() {
+	m := s.manager
+	p := s.pending[0]
+	s.pending = s.pending[1:]
+	p.entry.Name = highscore.CleanName(string(s.name))
+	m.lastName = p.entry.Name
+	s.highlight = m.scores.Add(s.cfg.Category(), orderFor(s.cfg), p.entry)
+	if err := m.scores.Save(m.opts.ScoresPath); err != nil {
+		log.Printf("ошибка при сохранении рекордов: %v", err)
+	}
+	log.Printf("рекорд игрока %s в режиме %s: %d очков за %s, место %d", p.entry.Name, s.cfg.Title(), p.entry.Score, p.entry.Duration, s.highlight+1)
+}
+
+// Draw отрисовывает ввод имени или итоги игры
+func (s *resultsScene) Draw(screen *ebiten.Image) {
+	face := s.manager.fontFace
+	if len(s.pending) > 0 {
+		p := s.pending[0]
+		ui.DrawBackground(screen)
+		result := fmt.Sprintf("Score: %d  Lines: %d  Level: %d", p.entry.Score, p.entry.Lines, p.entry.Level)
+		if s.cfg.Mode.Race() {
+			result = fmt.Sprintf("Time: %s  Pieces: %d", game.FormatTimer(p.entry.Duration), p.entry.Pieces)
+		}
+		lines := []string{
+			fmt.Sprintf("New high score! Player %d", p.player+1),
+			result,
+			"",
+			fmt.Sprintf("Name: %s_", string(s.name)),
+		}
+		for i, line := range lines {
+			text.Draw(screen, line, face, ui.MarginX, ui.MarginY+i*ui.LineHeight, ui.TextColor)
+		}
+		ui.DrawHelp(screen, face, "Enter: save  Esc: skip")
+		return
+	}
+
+	s.menu.Draw(screen, face)
+	// Итоги каждого игрока выводятся в отдельной колонке шириной с его поле
+	top := ui.MarginY + (len(s.menu.Items)+3)*ui.LineHeight
+	columnWidth, _ := game.ScreenSize(1)
+	for i, e := range s.engines {
+		entry := entryFor(e)
+		lines := []string{
+			fmt.Sprintf("Score: %d  Lines: %d  Level: %d", e.Score, e.Lines, e.Level),
+			fmt.Sprintf("Time: %s  Pieces: %d  PPS: %.2f", formatClock(e.Time), e.Pieces, entry.PPS()),
+		}
+		if s.cfg.Mode == engine.ModeSprint {
+			lines = []string{
+				fmt.Sprintf("Time: %s  Lines: %d/%d", game.FormatTimer(e.Time), e.Lines, s.cfg.LineGoal),
+				fmt.Sprintf("Pieces: %d  PPS: %.2f  KPP: %.2f  Finesse: %d", e.Pieces, e.PPS(), e.KPP(), e.FinesseFaults),
+			}
+			lines = append(lines, s.splitLines(e)...)
+		}
+		if s.cfg.Mode == engine.ModeCheese {
+			lines = []string{
+				fmt.Sprintf("Time: %s  Garbage: %d/%d", game.FormatTimer(e.Time), s.cfg.GarbageRows-e.GarbageLeft, s.cfg.GarbageRows),
+				fmt.Sprintf("Pieces: %d  PPS: %.2f  KPP: %.2f  Finesse: %d", e.Pieces, e.PPS(), e.KPP(), e.FinesseFaults),
+			}
+		}
+		if s.cfg.Mode == engine.ModeUltra {
+			lines = append(lines, clearLines(e.Clears)...)
+		}
+		if len(s.engines) > 1 {
+			lines = append([]string{fmt.Sprintf("Player %d: %s", i+1, e.Reason.Title())}, lines...)
+		}
+		for j, line := range lines {
+			text.Draw(screen, line, face, ui.MarginX+i*columnWidth, top+j*ui.LineHeight, ui.TextColor)
+		}
+	}
+}
+
+// splitLines возвращает промежуточные времена спринта с разницей относительно личного рекорда,
+// каким он был до этой игры
+func (s *resultsScene) splitLines(e *engine.Engine) []string {
+	best := s.best
+	var lines []string
+	for i, split := range e.Splits {
+		line := fmt.Sprintf("%3dL %s", (i+1)*10, game.FormatTimer(split))
+		if i < len(best) {
+			line += "  " + game.FormatDelta(split-best[i])
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// clearLines возвращает разбивку очисток по видам
+func clearLines(c engine.ClearStats) []string {
+	return []string{
+		fmt.Sprintf("Single %d  Double %d  Triple %d  Tetris %d", c.Lines[1], c.Lines[2], c.Lines[3], c.Lines[4]),
+		fmt.Sprintf("T-Spin %d  Single %d  Double %d  Triple %d", c.TSpins[0], c.TSpins[1], c.TSpins[2], c.TSpins[3]),
+		fmt.Sprintf("T-Spin Mini %d  Single %d  Double %d", c.TSpinMinis[0], c.TSpinMinis[1], c.TSpinMinis[2]),
+		fmt.Sprintf("B2B %d  Perfect Clear %d  Max combo %d", c.BackToBacks, c.PerfectClears, c.MaxCombo),
+	}
+}
@@ -0,0 +1,254 @@
+package scene
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"tetris/internal/engine"
+	"tetris/internal/game"
+	"tetris/internal/highscore"
+	"tetris/internal/input"
+	"tetris/internal/replay"
+	"tetris/internal/savegame"
+	"tetris/internal/settings"
+	"tetris/internal/ui"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Scene - экран приложения: главное меню, выбор режима, настройки, игра и т.д.
+type Scene interface {
+	Update() error
+	Draw(screen *ebiten.Image)
+}
+
+// Options задает параметры приложения, общие для всех экранов
+type Options struct {
+	Config          engine.Config         // Параметры игры, не вынесенные в настройки (зерно, последовательность фигур)
+	Settings        settings.Settings     // Настройки этого запуска: сохраненные с учетом флагов командной строки
+	SavedSettings   settings.Settings     // Настройки из файла без флагов командной строки: только они сохраняются
+	SettingsPath    string                // Файл настроек (пусто - не сохранять)
+	Bindings        input.Bindings        // Раскладка клавиатуры
+	BindingsPath    string                // Файл раскладки клавиш (пусто - не сохранять)
+	GamepadProfiles input.GamepadProfiles // Раскладки геймпадов
+	RecordPath      string                // Файл, в который записывается повтор последней игры
+	Replay          *replay.Replay        // Повтор для просмотра вместо главного меню
+	SaveDir         string                // Каталог сохранений (пусто - сохранения недоступны)
+	Autosave        bool                  // Сохранять игру при выходе и продолжать при запуске
+	ScoresPath      string                // Файл таблицы рекордов (пусто - рекорды не ведутся)
+}
+
+// Manager переключает экраны и хранит то, что переживает отдельную игру: настройки, раскладку и рекорды
+type Manager struct {
+	opts     Options
+	current  Scene
+	cfg      engine.Config     // Параметры следующей игры, зерно увеличивается с каждой игрой
+	settings settings.Settings // Текущие настройки с учетом флагов командной строки
+	saved    settings.Settings // Настройки для сохранения в файл: флаги командной строки в них не попадают
+	keyboard *input.Keyboard   // Клавиатура с раскладкой первого игрока
+	scores   *highscore.Table  // Таблица рекордов (nil, если рекорды не ведутся)
+	lastName string            // Имя, введенное для последнего рекорда
+	game     *game.Game        // Идущая игра (nil вне игры)
+	quit     bool              // Выбран выход из приложения
+	fontFace font.Face
+}
+
+// NewManager создает приложение и открывает первый экран: просмотр повтора, продолжение
+// сохраненной при выходе игры или главное меню
+func NewManager(opts Options) (*Manager, error) {
+	m := &Manager{
+		opts:     opts,
+		cfg:      opts.Config,
+		settings: opts.Settings,
+		saved:    opts.SavedSettings,
+		keyboard: &input.Keyboard{Bindings: opts.Bindings},
+		fontFace: basicfont.Face7x13,
+	}
+	if opts.ScoresPath != "" {
+		var err error
+		if m.scores, err = highscore.Load(opts.ScoresPath); err != nil {
+			log.Printf("таблица рекордов начата заново: %v", err)
+		}
+	}
+	if opts.Replay != nil {
+		g, err := game.NewGame(opts.Replay.Config, game.Options{Keyboard: m.keyboard, Replay: opts.Replay})
+		if err != nil {
+			return nil, err
+		}
+		m.play(g)
+		return m, nil
+	}
+	title := newTitle(m)
+	if opts.Autosave && opts.SaveDir != "" {
+		g, err := m.resumeAutosave()
+		if err != nil {
+			log.Printf("игра начинается с главного меню: %v", err)
+			title.menu.Message = "Saved game could not be resumed"
+		} else if g != nil {
+			m.play(g)
+			return m, nil
+		}
+	}
+	m.switchTo(title)
+	return m, nil
+}
+
+// resumeAutosave продолжает игру, сохраненную при прошлом выходе, или возвращает nil, если сохранения нет.
+// Сохранение, которое не удалось продолжить, откладывается в сторону, чтобы не мешать следующим запускам.
+func (m *Manager) resumeAutosave() (*game.Game, error) {
+	path := savegame.AutosavePath(m.opts.SaveDir)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	f, err := savegame.Load(path)
+	var g *game.Game
+	if err == nil {
+		// Количество игроков берется из сохранения, а не из настроек
+		g, err = m.newGame(f.Players[0].Config, len(f.Players), true)
+	}
+	if err != nil {
+		backup, setAsideErr := savegame.SetAside(path)
+		if setAsideErr != nil {
+			return nil, errors.Join(err, setAsideErr)
+		}
+		return nil, fmt.Errorf("%w; сохранение перенесено в %s", err, backup)
+	}
+	return g, nil
+}
+
+// Update обновляет текущий экран
+func (m *Manager) Update() error {
+	return m.current.Update()
+}
+
+// Draw отрисовывает текущий экран
+func (m *Manager) Draw(screen *ebiten.Image) {
+	m.current.Draw(screen)
+}
+
+// Layout задает размер экрана: он рассчитан на поля всех игроков
+func (m *Manager) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if m.game != nil {
+		return m.game.Layout(outsideWidth, outsideHeight)
+	}
+	return game.ScreenSize(m.settings.Players)
+}
+
+// Close завершает идущую игру при выходе из приложения: сохраняет повтор и незаконченную игру
+func (m *Manager) Close() {
+	if m.game == nil {
+		return
+	}
+	if err := m.game.SaveOnQuit(); err != nil {
+		log.Printf("ошибка при сохранении игры: %v", err)
+	}
+	m.endGame()
+}
+
+// switchTo открывает экран s
+func (m *Manager) switchTo(s Scene) {
+	m.current = s
+}
+
+// newGame создает игру с текущими настройками управления каждого игрока
+func (m *Manager) newGame(cfg engine.Config, players int, resume bool) (*game.Game, error) {
+	var best []time.Duration
+	if m.scores != nil && cfg.Ranked() {
+		// По ходу игры время сравнивается с промежуточными временами лучшего результата
+		if e, ok := m.scores.Best(cfg.Category()); ok {
+			best = e.Splits
+		}
+	}
+	return game.NewGame(cfg, game.Options{
+		Players:         players,
+		Handling:        m.settings.PlayerHandling(players),
+		Keyboard:        m.keyboard,
+		GamepadProfiles: m.opts.GamepadProfiles,
+		RecordPath:      m.opts.RecordPath,
+		SaveDir:         m.opts.SaveDir,
+		Autosave:        m.opts.Autosave,
+		Resume:          resume,
+		PersonalBest:    best,
+	})
+}
+
+// startGame начинает новую игру в режиме mode с текущими настройками
+func (m *Manager) startGame(mode engine.Mode) {
+	cfg := m.cfg
+	cfg.Mode = mode
+	m.settings.Apply(&cfg)
+	if choices := cheeseChoices(cfg.Height); mode == engine.ModeCheese && len(choices) > 0 {
+		// Количество мусора из файла может не подходить полю, заданному флагом: игра идет с вариантом,
+		// показанным в меню, а настройки остаются как были
+		cfg.GarbageRows = choices[cheeseIndex(choices, cfg.GarbageRows)]
+	}
+	g, err := m.newGame(cfg, m.settings.Players, false)
+	if err != nil {
+		log.Printf("не удалось начать игру: %v", err)
+		return
+	}
+	// Следующая игра получит другую, но воспроизводимую последовательность фигур
+	m.cfg.Seed++
+	log.Printf("новая игра: режим %s, зерно %d", cfg.Mode, cfg.Seed)
+	m.play(g)
+}
+
+// play открывает экран игры g
+func (m *Manager) play(g *game.Game) {
+	m.game = g
+	m.switchTo(&gameScene{manager: m, game: g})
+}
+
+// endGame сохраняет повтор законченной игры
+func (m *Manager) endGame() {
+	if err := m.game.SaveRecording(); err != nil {
+		log.Printf("ошибка при сохранении повтора: %v", err)
+	}
+	m.game = nil
+}
+
+// changeSettings меняет настройку, выбранную игроком в меню. Изменение применяется и к текущим,
+// и к сохраняемым настройкам, а значения флагов командной строки, которых игрок не менял, не сохраняются.
+func (m *Manager) changeSettings(change func(s *settings.Settings)) {
+	change(&m.settings)
+	change(&m.saved)
+}
+
+// saveSettings сохраняет настройки в файл
+func (m *Manager) saveSettings() {
+	if m.opts.SettingsPath == "" {
+		return
+	}
+	if err := m.saved.Validate(); err != nil {
+		log.Printf("настройки не сохранены: %v", err)
+		return
+	}
+	if err := m.saved.Save(m.opts.SettingsPath); err != nil {
+		log.Printf("ошибка при сохранении настроек: %v", err)
+	}
+}
+
+// menuScene - экран, состоящий из одного меню
+type menuScene struct {
+	manager *Manager
+	menu    *ui.Menu
+	back    func() // Действие по кнопке «назад» (nil - ничего не делать)
+}
+
+// Update обрабатывает навигацию по меню
+func (s *menuScene) Update() error {
+	if s.menu.Update(input.ReadMenuInput()) && s.back != nil {
+		s.back()
+	}
+	return nil
+}
+
+// Draw отрисовывает меню
+func (s *menuScene) Draw(screen *ebiten.Image) {
+	s.menu.Draw(screen, s.manager.fontFace)
+}
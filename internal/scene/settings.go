@@ -0,0 +1,115 @@
+package scene
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"tetris/internal/engine"
+	"tetris/internal/figure"
+	"tetris/internal/settings"
+	"tetris/internal/ui"
+	"time"
+)
+
+// Варианты значений настроек на экране настроек
+var (
+	lockDelayChoices  = []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, 750 * time.Millisecond, time.Second}
+	clearDelayChoices = []time.Duration{0, 150 * time.Millisecond, 300 * time.Millisecond, 500 * time.Millisecond}
+	entryDelayChoices = []time.Duration{0, 50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond}
+	dasChoices        = []time.Duration{50 * time.Millisecond, 83 * time.Millisecond, 100 * time.Millisecond, 133 * time.Millisecond, 167 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond, 300 * time.Millisecond}
+	arrChoices        = []time.Duration{0, 10 * time.Millisecond, 17 * time.Millisecond, 33 * time.Millisecond, 50 * time.Millisecond, 83 * time.Millisecond}
+	dasCutChoices     = []time.Duration{0, 17 * time.Millisecond, 33 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond}
+	sdfChoices        = []int{0, 5, 10, 20, 40}
+	messinessChoices  = []int{0, 10, 30, 50, 100}
+	garbageChoices    = []time.Duration{0, 3 * time.Second, 5 * time.Second, 8 * time.Second, 12 * time.Second}
+)
+
+// newSettings создает экран настроек, на котором показано управление игрока player (с нуля).
+// Настройки сохраняются при выходе с экрана.
+func newSettings(m *Manager, player int) *menuScene {
+	st, h := m.settings, m.settings.Handling[player]
+	menu := &ui.Menu{Title: "Settings"}
+	back := func() {
+		m.saveSettings()
+		m.switchTo(newTitle(m))
+	}
+	menu.Items = []*ui.Item{
+		option(m, "Players", []int{1, 2, 3, 4}, st.Players, strconv.Itoa, func(s *settings.Settings, v int) { s.Players = v }),
+		option(m, "Randomizer", []figure.RandomizerKind{figure.RandomizerBag7, figure.RandomizerBag14, figure.RandomizerRandom, figure.RandomizerHistory}, st.Randomizer,
+			func(k figure.RandomizerKind) string { return string(k) }, func(s *settings.Settings, k figure.RandomizerKind) { s.Randomizer = k }),
+		option(m, "Field width", []int{4, 6, 8, 10, 12, 16}, st.Width, strconv.Itoa, func(s *settings.Settings, v int) { s.Width = v }),
+		option(m, "Field height", []int{10, 16, 20, 24, 30, 40}, st.Height, strconv.Itoa, func(s *settings.Settings, v int) {
+			// Мусор режима Cheese должен поместиться на более низкое поле
+			s.Height, s.GarbageRows = v, min(s.GarbageRows, engine.MaxGarbageRows(v))
+		}),
+		option(m, "Next pieces", []int{1, 2, 3, 4, 5, 6}, st.NextCount, strconv.Itoa, func(s *settings.Settings, v int) { s.NextCount = v }),
+		option(m, "Lock mode", []engine.LockMode{engine.LockExtended, engine.LockInfinity, engine.LockClassic}, st.LockMode,
+			func(l engine.LockMode) string { return string(l) }, func(s *settings.Settings, l engine.LockMode) { s.LockMode = l }),
+		option(m, "Lock delay", lockDelayChoices, st.LockDelay, time.Duration.String, func(s *settings.Settings, d time.Duration) { s.LockDelay = d }),
+		option(m, "Line clear", clearDelayChoices, st.LineClearDelay, time.Duration.String, func(s *settings.Settings, d time.Duration) { s.LineClearDelay = d }),
+		option(m, "Entry delay", entryDelayChoices, st.EntryDelay, time.Duration.String, func(s *settings.Settings, d time.Duration) { s.EntryDelay = d }),
+		handlingPlayer(m, player),
+		option(m, "DAS", dasChoices, h.DAS, time.Duration.String, func(s *settings.Settings, d time.Duration) { s.Handling[player].DAS = d }),
+		option(m, "ARR", arrChoices, h.ARR, time.Duration.String, func(s *settings.Settings, d time.Duration) { s.Handling[player].ARR = d }),
+		option(m, "DAS cut", dasCutChoices, h.DASCut, time.Duration.String, func(s *settings.Settings, d time.Duration) { s.Handling[player].DASCut = d }),
+		option(m, "Soft drop", sdfChoices, h.SDF, formatSDF, func(s *settings.Settings, v int) { s.Handling[player].SDF = v }),
+		option(m, "Cheese mess", messinessChoices, st.Messiness, func(v int) string { return strconv.Itoa(v) + "%" }, func(s *settings.Settings, v int) { s.Messiness = v }),
+		option(m, "Garbage rise", garbageChoices, st.GarbageInterval, formatGarbageInterval, func(s *settings.Settings, d time.Duration) { s.GarbageInterval = d }),
+		{Label: "Controls", OnSelect: func() { m.switchTo(newControls(m, m.current)) }},
+		{Label: "Defaults", OnSelect: func() {
+			m.changeSettings(func(s *settings.Settings) {
+				players := s.Players
+				*s = settings.Default()
+				s.Players = players
+			})
+			m.switchTo(newSettings(m, player))
+		}},
+		{Label: "Back", OnSelect: back},
+	}
+	return &menuScene{manager: m, menu: menu, back: back}
+}
+
+// handlingPlayer создает пункт выбора игрока, чье управление (DAS/ARR/SDF) показано ниже.
+// При смене игрока экран строится заново, курсор остается на этом пункте.
+func handlingPlayer(m *Manager, player int) *ui.Item {
+	item := &ui.Item{Label: "Handling of", Index: player}
+	for i := range settings.MaxPlayers {
+		item.Values = append(item.Values, "player "+strconv.Itoa(i+1))
+	}
+	item.OnChange = func(i int) {
+		s := newSettings(m, i)
+		s.menu.Selected = slices.IndexFunc(s.menu.Items, func(it *ui.Item) bool { return it.Label == item.Label })
+		m.switchTo(s)
+	}
+	return item
+}
+
+// option создает пункт меню с вариантами values; если текущего значения нет среди вариантов, оно добавляется.
+// Выбранное значение записывается функцией set в настройки приложения.
+func option[T comparable](m *Manager, label string, values []T, current T, format func(T) string, set func(*settings.Settings, T)) *ui.Item {
+	if !slices.Contains(values, current) {
+		values = append(slices.Clone(values), current)
+	}
+	item := &ui.Item{Label: label, Index: slices.Index(values, current)}
+	for _, v := range values {
+		item.Values = append(item.Values, format(v))
+	}
+	item.OnChange = func(i int) { m.changeSettings(func(s *settings.Settings) { set(s, values[i]) }) }
+	return item
+}
+
+// formatSDF подписывает ускорение падения
+func formatSDF(sdf int) string {
+	if sdf == 0 {
+		return "instant"
+	}
+	return fmt.Sprintf("x%d", sdf)
+}
+
+// formatGarbageInterval подписывает интервал подъема мусора
+func formatGarbageInterval(d time.Duration) string {
+	if d == 0 {
+		return "off"
+	}
+	return "every " + d.String()
+}
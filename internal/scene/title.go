@@ -0,0 +1,42 @@
+package scene
+
+import (
+	"tetris/internal/engine"
+	"tetris/internal/ui"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// newTitle создает главное меню
+func newTitle(m *Manager) *titleScene {
+	menu := &ui.Menu{Title: "TETRIS"}
+	menu.Items = append(menu.Items, &ui.Item{Label: "Play", OnSelect: func() { m.switchTo(newModeSelect(m)) }})
+	if m.scores != nil {
+		menu.Items = append(menu.Items, &ui.Item{Label: "High scores", OnSelect: func() {
+			cfg := engine.DefaultConfig()
+			m.settings.Apply(&cfg)
+			m.switchTo(newScores(m, cfg, -1, newTitle(m)))
+		}})
+	}
+	menu.Items = append(menu.Items,
+		&ui.Item{Label: "Settings", OnSelect: func() { m.switchTo(newSettings(m, 0)) }},
+		&ui.Item{Label: "Quit", OnSelect: func() { m.quit = true }},
+	)
+	return &titleScene{menuScene{manager: m, menu: menu}}
+}
+
+// titleScene - главное меню; выбор Quit завершает приложение
+type titleScene struct {
+	menuScene
+}
+
+// Update обрабатывает навигацию и выход из приложения
+func (s *titleScene) Update() error {
+	if err := s.menuScene.Update(); err != nil {
+		return err
+	}
+	if s.manager.quit {
+		return ebiten.Termination
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"tetris/internal/engine"
+	"tetris/internal/figure"
+	"tetris/internal/storage"
+	"time"
+)
+
+// MaxPlayers - максимальное количество локальных игроков
+const MaxPlayers = 4
+
+// Settings - настройки, которые меняются на экране настроек и сохраняются между запусками
+type Settings struct {
+	Players    int                   `json:"players"`     // Количество локальных игроков (1-4)
+	Randomizer figure.RandomizerKind `json:"randomizer"`  // Алгоритм генератора фигур
+	NextCount  int                   `json:"next_count"`  // Длина очереди следующих фигур
+	Width      int                   `json:"width"`       // Ширина поля в клетках
+	Height     int                   `json:"height"`      // Высота видимой части поля в клетках
+	StartLevel int                   `json:"start_level"` // Начальный уровень
+	LineGoal   int                   `json:"line_goal"`   // Цель режима Sprint в линиях
+	TimeLimit  time.Duration         `json:"time_limit"`  // Длительность режима Ultra
+	//Cheese
+	GarbageRows     int             `json:"garbage_rows"`     // Начальные мусорные ряды
+	Messiness       int             `json:"messiness"`        // Вероятность смены колонки дырки, %
+	GarbageInterval time.Duration   `json:"garbage_interval"` // Интервал подъема мусора (0 - не поднимается)
+	LockMode        engine.LockMode `json:"lock_mode"`        // Правило сброса задержки фиксации
+	LockDelay       time.Duration   `json:"lock_delay"`       // Задержка фиксации
+	LineClearDelay  time.Duration   `json:"line_clear_delay"` // Задержка очистки линий
+	EntryDelay      time.Duration   `json:"entry_delay"`      // Задержка появления фигуры (ARE)
+	Handling        Handlings       `json:"handling"`         // DAS/ARR/SDF каждого игрока
+}
+
+// Handlings - настройки управления каждого игрока, первая - игрока с клавиатурой
+type Handlings [MaxPlayers]engine.Handling
+
+// UnmarshalJSON читает настройки управления списком по игрокам. В файлах настроек, записанных до
+// появления настроек по игрокам, управление одно на всех: тогда оно достается каждому игроку.
+// Игроки, которых нет в списке, сохраняют прежние настройки.
+func (h *Handlings) UnmarshalJSON(data []byte) error {
+	single := h[0]
+	if err := json.Unmarshal(data, &single); err == nil {
+		for i := range h {
+			h[i] = single
+		}
+		return nil
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	if len(list) > len(h) {
+		return fmt.Errorf("настроек управления %d, игроков не больше %d", len(list), len(h))
+	}
+	// Поля, которых нет у игрока в файле, остаются прежними, как и у остальных настроек
+	for i, raw := range list {
+		if err := json.Unmarshal(raw, &h[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Default возвращает настройки по умолчанию
+func Default() Settings {
+	return FromConfig(engine.DefaultConfig(), 1)
+}
+
+// FromConfig берет настройки из параметров игры. Управление у всех игроков одинаковое.
+func FromConfig(cfg engine.Config, players int) Settings {
+	var handling Handlings
+	for i := range handling {
+		handling[i] = cfg.Handling
+	}
+	return Settings{
+		Players:         players,
+		Randomizer:      cfg.Randomizer,
+		NextCount:       cfg.NextCount,
+		Width:           cfg.Width,
+		Height:          cfg.Height,
+		StartLevel:      cfg.StartLevel,
+		LineGoal:        cfg.LineGoal,
+		TimeLimit:       cfg.TimeLimit,
+		GarbageRows:     cfg.GarbageRows,
+		Messiness:       cfg.Messiness,
+		GarbageInterval: cfg.GarbageInterval,
+		LockMode:        cfg.LockMode,
+		LockDelay:       cfg.LockDelay,
+		LineClearDelay:  cfg.LineClearDelay,
+		EntryDelay:      cfg.EntryDelay,
+		Handling:        handling,
+	}
+}
+
+// Apply переносит настройки в параметры игры. Управление берется у первого игрока,
+// остальным игрокам оно передается отдельно (см. PlayerHandling).
+func (s Settings) Apply(cfg *engine.Config) {
+	cfg.Randomizer = s.Randomizer
+	cfg.NextCount = s.NextCount
+	cfg.Width = s.Width
+	cfg.Height = s.Height
+	cfg.StartLevel = s.StartLevel
+	cfg.LineGoal = s.LineGoal
+	cfg.TimeLimit = s.TimeLimit
+	cfg.GarbageRows = s.GarbageRows
+	cfg.Messiness = s.Messiness
+	cfg.GarbageInterval = s.GarbageInterval
+	cfg.LockMode = s.LockMode
+	cfg.LockDelay = s.LockDelay
+	cfg.LineClearDelay = s.LineClearDelay
+	cfg.EntryDelay = s.EntryDelay
+	cfg.Handling = s.Handling[0]
+}
+
+// PlayerHandling возвращает настройки управления первых players игроков
+func (s Settings) PlayerHandling(players int) []engine.Handling {
+	return append([]engine.Handling(nil), s.Handling[:min(max(players, 0), MaxPlayers)]...)
+}
+
+// Validate проверяет, что настройки допустимы
+func (s Settings) Validate() error {
+	if s.Players < 1 || s.Players > MaxPlayers {
+		return fmt.Errorf("количество игроков %d вне диапазона 1-%d", s.Players, MaxPlayers)
+	}
+	// Последовательность фигур в настройках не хранится, поэтому генератор sequence здесь не пройдет проверку
+	if _, err := figure.NewRandomizer(s.Randomizer, 0, nil); err != nil {
+		return err
+	}
+	for i, h := range s.Handling {
+		if err := h.Validate(); err != nil {
+			return fmt.Errorf("управление игрока %d: %w", i+1, err)
+		}
+	}
+	// Настройки всех режимов проверяются сразу, даже если сейчас выбран другой
+	for _, mode := range engine.Modes {
+		cfg := engine.DefaultConfig()
+		s.Apply(&cfg)
+		cfg.Mode = mode
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultPath возвращает путь к файлу настроек в каталоге настроек пользователя
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить каталог настроек: %w", err)
+	}
+	return filepath.Join(dir, "tetris", "settings.json"), nil
+}
+
+// Load читает настройки из JSON-файла. Если файла нет или он неверен, возвращаются настройки по умолчанию.
+func Load(path string) (Settings, error) {
+	s := Default()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("не удалось прочитать настройки %s: %w", path, err)
+	}
+	// Поля, которых нет в файле, остаются по умолчанию
+	loaded := s
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return s, fmt.Errorf("неверный формат настроек %s: %w", path, err)
+	}
+	if err := loaded.Validate(); err != nil {
+		return s, fmt.Errorf("неверные настройки %s: %w", path, err)
+	}
+	return loaded, nil
+}
+
+// Save атомарно записывает настройки в JSON-файл
+func (s Settings) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось закодировать настройки: %w", err)
+	}
+	if err := storage.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("не удалось сохранить настройки: %w", err)
+	}
+	return nil
+}
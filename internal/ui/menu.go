@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"tetris/internal/input"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+const (
+	LineHeight = 20 // LineHeight - Высота строки меню
+	MarginX    = 20 // MarginX - Отступ текста от левого края экрана
+	MarginY    = 30 // MarginY - Отступ заголовка от верхнего края экрана
+	labelWidth = 16 // Ширина подписи пункта в символах, после неё выводится значение
+)
+
+var (
+	BackgroundColor = color.RGBA{230, 230, 230, 255} // BackgroundColor - Фон экранов меню
+	SelectedColor   = color.RGBA{255, 220, 120, 255} // SelectedColor - Подсветка выбранной строки
+	TextColor       = color.RGBA{0, 0, 0, 255}       // TextColor - Цвет текста
+)
+
+// Item - пункт меню. Пункт со списком Values переключает значение стрелками влево/вправо.
+type Item struct {
+	Label    string
+	Values   []string        // Варианты значения (nil - пункт без значения)
+	Index    int             // Выбранный вариант
+	Detail   string          // Пояснение справа от подписи
+	OnSelect func()          // Вызывается при выборе пункта
+	OnChange func(index int) // Вызывается при смене значения
+}
+
+// Value возвращает выбранное значение пункта
+func (it *Item) Value() string {
+	if len(it.Values) == 0 {
+		return ""
+	}
+	return it.Values[it.Index]
+}
+
+// Menu - вертикальный список пунктов с навигацией с клавиатуры и геймпада
+type Menu struct {
+	Title    string
+	Items    []*Item
+	Selected int    // Выбранный пункт
+	Message  string // Результат последнего действия
+	Help     string // Подсказка внизу экрана
+}
+
+// Update обрабатывает навигацию и сообщает, нажата ли кнопка «назад»
+func (m *Menu) Update(in input.MenuInput) bool {
+	if len(m.Items) == 0 {
+		return in.Back
+	}
+	item := m.Items[m.Selected]
+	switch {
+	case in.Back:
+		return true
+	case in.Up:
+		m.Selected = (m.Selected + len(m.Items) - 1) % len(m.Items)
+	case in.Down:
+		m.Selected = (m.Selected + 1) % len(m.Items)
+	case in.Left && len(item.Values) > 0:
+		item.Index = (item.Index + len(item.Values) - 1) % len(item.Values)
+		if item.OnChange != nil {
+			item.OnChange(item.Index)
+		}
+	case in.Right && len(item.Values) > 0:
+		item.Index = (item.Index + 1) % len(item.Values)
+		if item.OnChange != nil {
+			item.OnChange(item.Index)
+		}
+	case in.Confirm && item.OnSelect != nil:
+		item.OnSelect()
+	}
+	return false
+}
+
+// Draw отрисовывает меню на весь экран
+func (m *Menu) Draw(screen *ebiten.Image, face font.Face) {
+	DrawBackground(screen)
+	text.Draw(screen, m.Title, face, MarginX, MarginY, TextColor)
+	for i, item := range m.Items {
+		y := MarginY + (i+1)*LineHeight
+		if i == m.Selected {
+			DrawHighlight(screen, y)
+		}
+		line := item.Label
+		switch {
+		case len(item.Values) > 0:
+			line = fmt.Sprintf("%-*s < %s >", labelWidth, item.Label, item.Value())
+		case item.Detail != "":
+			line = fmt.Sprintf("%-*s %s", labelWidth, item.Label, item.Detail)
+		}
+		text.Draw(screen, line, face, MarginX, y, TextColor)
+	}
+	if m.Message != "" {
+		text.Draw(screen, m.Message, face, MarginX, MarginY+(len(m.Items)+2)*LineHeight, TextColor)
+	}
+	help := m.Help
+	if help == "" {
+		help = "Up/Down: select  Left/Right: change  Enter: choose  Esc: back"
+	}
+	DrawHelp(screen, face, help)
+}
+
+// DrawBackground заливает экран фоном меню
+func DrawBackground(screen *ebiten.Image) {
+	screen.Fill(BackgroundColor)
+}
+
+// DrawHighlight подсвечивает строку меню с базовой линией текста y
+func DrawHighlight(screen *ebiten.Image, y int) {
+	highlight := ebiten.NewImage(screen.Bounds().Dx()-2*MarginX+10, LineHeight)
+	highlight.Fill(SelectedColor)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(MarginX-5), float64(y-LineHeight+5))
+	screen.DrawImage(highlight, op)
+}
+
+// DrawHelp выводит подсказку по клавишам внизу экрана
+func DrawHelp(screen *ebiten.Image, face font.Face, help string) {
+	text.Draw(screen, help, face, MarginX, screen.Bounds().Dy()-MarginY, TextColor)
+}